@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"golang.org/x/crypto/ssh"
+)
+
+var errNoRecords = errors.New("no records found") // Bucket exists, but holds no records.
+
+/*
+getValidity computes validity of a certificate at given moment.
+
+	'thisRevocation' Revocation of the certificate.
+	'thisFinish' End of certificate's validity, zero time if it never expires.
+	'thisNow' Moment the validity is computed for.
+*/
+func getValidity(thisRevocation tCertificateRevocation, thisFinish time.Time, thisNow time.Time) string {
+
+	if thisRevocation.IsRevoked() && thisNow.After(thisRevocation.RevokedAt) {
+		return REVOKED_STR
+	}
+
+	if !thisFinish.IsZero() && thisNow.After(thisFinish) {
+		return EXPIRED_STR
+	}
+
+	return VALID_STR
+}
+
+/*
+getRevocationReasonCodeStr returns reason code followed by its RFC 5280 name, e.g. 1 keyCompromise. Empty if not revoked.
+
+	'thisRevocation' Revocation of a certificate.
+*/
+func getRevocationReasonCodeStr(thisRevocation tCertificateRevocation) string {
+
+	if !thisRevocation.IsRevoked() {
+		return ""
+	}
+
+	reasonCode := strconv.Itoa(thisRevocation.ReasonCode)
+	if reasonStr, ok := getRevocationReasonStr()[thisRevocation.ReasonCode]; ok {
+		reasonCode += " " + reasonStr
+	}
+
+	return reasonCode
+}
+
+/*
+getRevocationSortCode returns reason code to be sorted by, -1 if not revoked.
+
+	'thisRevocation' Revocation of a certificate.
+*/
+func getRevocationSortCode(thisRevocation tCertificateRevocation) int {
+
+	if !thisRevocation.IsRevoked() {
+		return -1
+	}
+
+	return thisRevocation.ReasonCode
+}
+
+/*
+getRevocationMethod tells how certificate was revoked: over ACME, with mTLS or with token. Empty if not revoked or unknown.
+
+	'thisRevocation' Revocation of a certificate.
+*/
+func getRevocationMethod(thisRevocation tCertificateRevocation) string {
+	switch {
+	case !thisRevocation.IsRevoked():
+		return ""
+	case thisRevocation.ACME:
+		return REVOKED_BY_ACME
+	case thisRevocation.MTLS:
+		return REVOKED_BY_MTLS
+	case len(thisRevocation.TokenID) > 0:
+		return REVOKED_BY_TOKEN
+	default:
+		return ""
+	}
+}
+
+/*
+loadX509Certificates reads all x509 certificates joined with revocation and provisioner, validity computed as of now.
+Missing bucket gives error wrapping stepdb.ErrBucketNotFound, empty bucket gives errNoRecords.
+
+	'thisReader' Opened database.
+	'thisOnRecordError' Called for every malformed record, which is then skipped, or returned in strict mode.
+*/
+func loadX509Certificates(thisReader *stepdb.Reader, thisOnRecordError func(error)) ([]tX509CertificateProvisionerRevocation, error) {
+
+	var x509CertificatesProvisionersRevocations []tX509CertificateProvisionerRevocation
+
+	// Get records from the x509_certs bucket.
+	x509Iterator := thisReader.X509Certificates()
+	if err := x509Iterator.Err(); err != nil {
+		return nil, err
+	}
+	if x509Iterator.Len() == 0 {
+		return nil, errNoRecords
+	}
+
+	now := time.Now()
+
+	for x509Iterator.Next() {
+		// Get certificate joined with revocation and provisioner.
+		record, err := x509Iterator.Record()
+		if loggingLevel >= 2 { // Show info.
+			logInfo.Printf("Key: %s", record.Key)
+			logInfo.Printf("Value: %q", record.Value)
+		}
+		if err != nil {
+			thisOnRecordError(err)
+			if config.strict {
+				return nil, err
+			}
+			continue
+		}
+
+		if loggingLevel >= 2 { // Show info.
+			logInfo.Printf("Serial: %s", record.Certificate.SerialNumber.String())
+			logInfo.Printf("Subject: %s", record.Certificate.Subject)
+			logInfo.Printf("RevocationProvisionerID: %s", record.Revocation.ProvisionerID)
+			logInfo.Printf("Provisioner: %s", record.Data.Provisioner.Type)
+		}
+
+		// Populate the child, with validity info of the certificate.
+		x509CertificatesProvisionersRevocations = append(x509CertificatesProvisionersRevocations,
+			tX509CertificateProvisionerRevocation{
+				X509Certificate: *record.Certificate,
+				Validity:        getValidity(record.Revocation, record.Certificate.NotAfter, now),
+				X509Revocation:  record.Revocation,
+				X509Provisioner: record.Data.Provisioner,
+			})
+	}
+
+	return x509CertificatesProvisionersRevocations, nil
+}
+
+/*
+loadSshCertificates reads all ssh certificates joined with revocation, validity computed as of now.
+Missing bucket gives error wrapping stepdb.ErrBucketNotFound, empty bucket gives errNoRecords.
+
+	'thisReader' Opened database.
+	'thisOnRecordError' Called for every malformed record, which is then skipped, or returned in strict mode.
+*/
+func loadSshCertificates(thisReader *stepdb.Reader, thisOnRecordError func(error)) ([]tSshCertificateWithRevocation, error) {
+
+	var sshCertificatesWithRevocations []tSshCertificateWithRevocation
+
+	// Get records from the ssh_certs bucket.
+	sshIterator := thisReader.SSHCertificates()
+	if err := sshIterator.Err(); err != nil {
+		return nil, err
+	}
+	if sshIterator.Len() == 0 {
+		return nil, errNoRecords
+	}
+
+	now := time.Now()
+
+	for sshIterator.Next() {
+		// Get certificate joined with revocation.
+		record, err := sshIterator.Record()
+		if loggingLevel >= 2 { // Show info.
+			logInfo.Printf("Key: %s", record.Key)
+			logInfo.Printf("Value: %q", record.Value)
+		}
+		if err != nil {
+			thisOnRecordError(err)
+			if config.strict {
+				return nil, err
+			}
+			continue
+		}
+
+		if loggingLevel >= 2 { // Show info.
+			logInfo.Printf("Serial: %s", strconv.FormatUint(record.Certificate.Serial, 10))
+			logInfo.Printf("Subject: %s", strings.Join(record.Certificate.ValidPrincipals, ","))
+			logInfo.Printf("RevocationProvisionerID: %s", record.Revocation.ProvisionerID)
+		}
+
+		// Populate the child, with validity info of the certificate.
+		sshCertificatesWithRevocations = append(sshCertificatesWithRevocations,
+			tSshCertificateWithRevocation{
+				SshCertificate:           *record.Certificate,
+				Validity:                 getValidity(record.Revocation, getSshFinish(*record.Certificate), now),
+				SshCertificateRevocation: record.Revocation,
+			})
+	}
+
+	return sshCertificatesWithRevocations, nil
+}
+
+/*
+getSshFinish returns end of ssh certificate's validity, zero time if it never expires.
+
+	'thisCertificate' Certificate.
+*/
+func getSshFinish(thisCertificate ssh.Certificate) time.Time {
+
+	if thisCertificate.ValidBefore == ssh.CertTimeInfinity {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(thisCertificate.ValidBefore), 0)
+}
+
+/*
+getSelectedValidities returns validities selected with --valid, --revoked & --expired flags, or their equivalents.
+
+	'thisValid' Valid certificates selected.
+	'thisRevoked' Revoked certificates selected.
+	'thisExpired' Expired certificates selected.
+*/
+func getSelectedValidities(thisValid bool, thisRevoked bool, thisExpired bool) map[string]bool {
+	return map[string]bool{VALID_STR: thisValid, REVOKED_STR: thisRevoked, EXPIRED_STR: thisExpired}
+}
+
+/*
+sortX509Certificates sorts certificates in place, stable.
+
+	'thisX509CertsWithRevocations' Slice of certs.
+	'thisSortKeys' Sort keys, as returned by getX509SortKeys.
+*/
+func sortX509Certificates(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation, thisSortKeys []tSortKey) {
+
+	columns := getX509Columns()
+
+	slices.SortStableFunc(thisX509CertsWithRevocations, func(a, b tX509CertificateProvisionerRevocation) int {
+		return compareBySortKeys(thisSortKeys, func(thisIndex int) int {
+			column := columns[thisIndex]
+			if column.compare != nil {
+				return column.compare(a, b)
+			}
+			return strings.Compare(column.contentSource(a, config), column.contentSource(b, config))
+		})
+	})
+}
+
+/*
+sortSshCertificates sorts certificates in place, stable.
+
+	'thisSshCertificatesWithRevocations' Slice of certs.
+	'thisSortKeys' Sort keys, as returned by getSshSortKeys.
+*/
+func sortSshCertificates(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation, thisSortKeys []tSortKey) {
+
+	columns := getSshColumns()
+
+	slices.SortStableFunc(thisSshCertificatesWithRevocations, func(a, b tSshCertificateWithRevocation) int {
+		return compareBySortKeys(thisSortKeys, func(thisIndex int) int {
+			column := columns[thisIndex]
+			if column.compare != nil {
+				return column.compare(a, b)
+			}
+			return strings.Compare(column.contentSource(a, config), column.contentSource(b, config))
+		})
+	})
+}
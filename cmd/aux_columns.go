@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+/*
+selectColumns resolves --columns against ids of columns, returning indexes of columns to be shown, in order.
+Empty spec keeps the defaults. Spec of plain ids selects and orders columns, spec of +id and -id items adds to and
+removes from the defaults, keeping their natural order.
+
+	'thisIds' Ids of all columns, in natural order.
+	'thisDefaults' Whether each column is shown by default.
+	'thisSpec' Value of --columns.
+*/
+func selectColumns(thisIds []string, thisDefaults []bool, thisSpec string) ([]int, error) {
+
+	shown := slices.Clone(thisDefaults)
+	var ordered []int
+	var tweaked, selected bool
+
+	for _, item := range strings.Split(thisSpec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if len(item) == 0 {
+			continue
+		}
+
+		sign := item[0]
+		if sign == '+' || sign == '-' {
+			item = item[1:]
+			tweaked = true
+		} else {
+			selected = true
+		}
+
+		index := slices.Index(thisIds, item)
+		if index < 0 {
+			return nil, fmt.Errorf("unknown column %q, expected one of: %s", item, strings.Join(thisIds, ","))
+		}
+
+		switch sign {
+		case '+':
+			shown[index] = true
+		case '-':
+			shown[index] = false
+		default:
+			if slices.Contains(ordered, index) {
+				return nil, fmt.Errorf("column %q given twice", item)
+			}
+			ordered = append(ordered, index)
+		}
+	}
+
+	if tweaked && selected {
+		return nil, fmt.Errorf("columns %q mix plain ids with +id and -id items", thisSpec)
+	}
+	if selected {
+		return ordered, nil
+	}
+
+	for index := range thisIds {
+		if shown[index] {
+			ordered = append(ordered, index)
+		}
+	}
+
+	return ordered, nil
+}
+
+/*
+columnsArgs wraps cobra validator of arguments, so that none are expected with --list-columns.
+
+	'thisArgs' Validator used otherwise.
+*/
+func columnsArgs(thisArgs cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if config.listColumns {
+			return cobra.NoArgs(cmd, args)
+		}
+		return thisArgs(cmd, args)
+	}
+}
+
+/*
+emitColumnList prints ids of columns with their descriptions, marking those shown by default.
+
+	'thisIds' Ids of all columns, in natural order.
+	'thisDescriptions' Description of each column.
+	'thisDefaults' Whether each column is shown by default.
+*/
+func emitColumnList(thisIds []string, thisDescriptions []string, thisDefaults []bool) {
+
+	width := 0
+	for _, id := range thisIds {
+		width = max(width, len(id))
+	}
+
+	for index, id := range thisIds {
+		var shown string
+		if thisDefaults[index] {
+			shown = " (default)"
+		}
+		fmt.Printf("%-*s  %s%s\n", width, id, thisDescriptions[index], shown)
+	}
+}
+
+/*
+Sort key, column to be compared by.
+*/
+type tSortKey struct {
+	index      int  // Index of the column, in natural order.
+	descending bool // Reversed order.
+}
+
+/*
+parseSortKeys resolves --sort against ids of columns. Items are compared in order given, - prefix reverses order.
+
+	'thisIds' Ids of all columns, in natural order.
+	'thisSpec' Value of --sort, e.g. provisioner,-finish,subject.
+*/
+func parseSortKeys(thisIds []string, thisSpec string) ([]tSortKey, error) {
+
+	var sortKeys []tSortKey
+
+	for _, item := range strings.Split(thisSpec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if len(item) == 0 {
+			continue
+		}
+
+		var sortKey tSortKey
+		if item[0] == '-' || item[0] == '+' {
+			sortKey.descending = item[0] == '-'
+			item = item[1:]
+		}
+
+		if sortKey.index = slices.Index(thisIds, item); sortKey.index < 0 {
+			return nil, fmt.Errorf("unknown sort key %q, expected one of: %s", item, strings.Join(thisIds, ","))
+		}
+		sortKeys = append(sortKeys, sortKey)
+	}
+
+	return sortKeys, nil
+}
+
+/*
+compareBySortKeys compares two records key by key, until they differ.
+
+	'thisSortKeys' Sort keys, in order.
+	'thisCompare' Compares both records by column of given index.
+*/
+func compareBySortKeys(thisSortKeys []tSortKey, thisCompare func(int) int) int {
+
+	for _, sortKey := range thisSortKeys {
+		if result := thisCompare(sortKey.index); result != 0 {
+			if sortKey.descending {
+				return -result
+			}
+			return result
+		}
+	}
+
+	return 0
+}
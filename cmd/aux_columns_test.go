@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+func TestSelectColumns(t *testing.T) {
+
+	ids := []string{"serial", "subject", "provisioner", "finish", "validity"}
+	defaults := []bool{true, true, false, true, true}
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    []int
+		wantErr bool
+	}{
+		{"empty keeps defaults", "", []int{0, 1, 3, 4}, false},
+		{"blank items skipped", " , ,", []int{0, 1, 3, 4}, false},
+		{"plain ids in given order", "finish,serial", []int{3, 0}, false},
+		{"plain ids select hidden", "provisioner", []int{2}, false},
+		{"plain ids case and space", " Subject , VALIDITY ", []int{1, 4}, false},
+		{"add keeps natural order", "+provisioner", []int{0, 1, 2, 3, 4}, false},
+		{"remove", "-serial", []int{1, 3, 4}, false},
+		{"add and remove", "+provisioner,-finish,-validity", []int{0, 1, 2}, false},
+		{"add already shown", "+serial", []int{0, 1, 3, 4}, false},
+		{"remove all", "-serial,-subject,-finish,-validity", nil, false},
+		{"mixed", "serial,+provisioner", nil, true},
+		{"unknown plain id", "serial,nope", nil, true},
+		{"unknown tweaked id", "+nope", nil, true},
+		{"sign only", "+", nil, true},
+		{"duplicate plain id", "serial,finish,serial", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns, err := selectColumns(ids, defaults, test.spec)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("selectColumns(%q) error = %v, wantErr %v", test.spec, err, test.wantErr)
+			}
+			if !slices.Equal(columns, test.want) {
+				t.Errorf("selectColumns(%q) = %v, want %v", test.spec, columns, test.want)
+			}
+		})
+	}
+
+	// Defaults are not modified by tweaks.
+	if !slices.Equal(defaults, []bool{true, true, false, true, true}) {
+		t.Errorf("selectColumns modified defaults: %v", defaults)
+	}
+}
+
+func TestParseSortKeys(t *testing.T) {
+
+	ids := []string{"serial", "subject", "provisioner", "finish", "validity"}
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    []tSortKey
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"ascending", "finish", []tSortKey{{index: 3}}, false},
+		{"descending", "-finish", []tSortKey{{index: 3, descending: true}}, false},
+		{"explicit ascending", "+finish", []tSortKey{{index: 3}}, false},
+		{"several in given order", "provisioner,-finish,subject",
+			[]tSortKey{{index: 2}, {index: 3, descending: true}, {index: 1}}, false},
+		{"case and space", " -Serial , ", []tSortKey{{index: 0, descending: true}}, false},
+		{"unknown key", "finish,nope", nil, true},
+		{"sign only", "-", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sortKeys, err := parseSortKeys(ids, test.spec)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseSortKeys(%q) error = %v, wantErr %v", test.spec, err, test.wantErr)
+			}
+			if !slices.Equal(sortKeys, test.want) {
+				t.Errorf("parseSortKeys(%q) = %v, want %v", test.spec, sortKeys, test.want)
+			}
+		})
+	}
+}
+
+func TestCompareBySortKeys(t *testing.T) {
+
+	a := []int{1, 5, 3}
+	b := []int{1, 2, 4}
+	compare := func(thisIndex int) int { return cmp.Compare(a[thisIndex], b[thisIndex]) }
+
+	tests := []struct {
+		name     string
+		sortKeys []tSortKey
+		want     int
+	}{
+		{"none", nil, 0},
+		{"equal only", []tSortKey{{index: 0}}, 0},
+		{"first differing decides", []tSortKey{{index: 0}, {index: 1}, {index: 2}}, 1},
+		{"descending reverses", []tSortKey{{index: 0}, {index: 1, descending: true}}, -1},
+		{"order given", []tSortKey{{index: 2}, {index: 1}}, -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := compareBySortKeys(test.sortKeys, compare); result != test.want {
+				t.Errorf("compareBySortKeys(%v) = %d, want %d", test.sortKeys, result, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+/*
+getPublicKeyStr describes public key by its algorithm, with key size or curve, e.g. RSA 2048 or ECDSA P-256.
+
+	'thisPublicKey' Public key, as parsed by crypto/x509.
+*/
+func getPublicKeyStr(thisPublicKey any) string {
+	switch publicKey := thisPublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", publicKey.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + publicKey.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%T", publicKey)
+	}
+}
+
+/*
+getKeyUsageNames lists names of key usages set in x509 certificate, in bit order.
+
+	'thisCertificate' Certificate.
+*/
+func getKeyUsageNames(thisCertificate x509.Certificate) []string {
+
+	var names []string
+	for keyUsage := x509.KeyUsage(1); keyUsage <= x509.KeyUsageDecipherOnly; keyUsage <<= 1 {
+		if thisCertificate.KeyUsage&keyUsage != 0 {
+			names = append(names, getKeyUsageStr()[keyUsage])
+		}
+	}
+
+	return names
+}
+
+/*
+getExtKeyUsageNames lists names of extended key usages of x509 certificate, unknown ones as object identifiers.
+
+	'thisCertificate' Certificate.
+*/
+func getExtKeyUsageNames(thisCertificate x509.Certificate) []string {
+
+	var names []string
+	for _, extKeyUsage := range thisCertificate.ExtKeyUsage {
+		name, ok := getExtKeyUsageStr()[extKeyUsage]
+		if !ok {
+			name = fmt.Sprintf("%d", extKeyUsage)
+		}
+		names = append(names, name)
+	}
+	for _, oid := range thisCertificate.UnknownExtKeyUsage {
+		names = append(names, oid.String())
+	}
+
+	return names
+}
+
+/*
+formatLifetime formats duration in days and hours, e.g. 90d or 1d12h; shorter ones as Go does, e.g. 5m0s.
+
+	'thisLifetime' Duration.
+*/
+func formatLifetime(thisLifetime time.Duration) string {
+
+	if thisLifetime < time.Hour || thisLifetime%time.Hour != 0 {
+		return thisLifetime.String()
+	}
+
+	days, hours := thisLifetime/(24*time.Hour), (thisLifetime%(24*time.Hour))/time.Hour
+	switch {
+	case days == 0:
+		return fmt.Sprintf("%dh", hours)
+	case hours == 0:
+		return fmt.Sprintf("%dd", days)
+	default:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+}
+
+/*
+getSshPublicKeyStr describes ssh public key as getPublicKeyStr does, or by its ssh type when not convertible.
+
+	'thisPublicKey' Public key, nil gives empty string.
+*/
+func getSshPublicKeyStr(thisPublicKey ssh.PublicKey) string {
+
+	if thisPublicKey == nil {
+		return ""
+	}
+	if cryptoPublicKey, ok := thisPublicKey.(ssh.CryptoPublicKey); ok {
+		return getPublicKeyStr(cryptoPublicKey.CryptoPublicKey())
+	}
+
+	return thisPublicKey.Type()
+}
+
+/*
+getSshFingerprint returns SHA256 fingerprint of ssh public key, as ssh-keygen -l does.
+
+	'thisPublicKey' Public key, nil gives empty string.
+*/
+func getSshFingerprint(thisPublicKey ssh.PublicKey) string {
+
+	if thisPublicKey == nil {
+		return ""
+	}
+
+	return ssh.FingerprintSHA256(thisPublicKey)
+}
+
+/*
+getSshOptions lists critical options or extensions of ssh certificate, sorted, as name=value or bare name if empty.
+
+	'thisOptions' Critical options or extensions.
+*/
+func getSshOptions(thisOptions map[string]string) []string {
+
+	var options []string
+	for name, value := range thisOptions {
+		if len(value) > 0 {
+			name += "=" + value
+		}
+		options = append(options, name)
+	}
+	slices.Sort(options)
+
+	return options
+}
+
+/*
+getSshLifetime returns total lifetime of ssh certificate, maximal duration if it never expires.
+
+	'thisCertificate' Certificate.
+*/
+func getSshLifetime(thisCertificate ssh.Certificate) time.Duration {
+
+	if thisCertificate.ValidBefore == ssh.CertTimeInfinity {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Unix(int64(thisCertificate.ValidBefore), 0).Sub(time.Unix(int64(thisCertificate.ValidAfter), 0))
+}
+
+/*
+getSshLifetimeStr formats total lifetime of ssh certificate, as formatLifetime does, or forever.
+
+	'thisCertificate' Certificate.
+*/
+func getSshLifetimeStr(thisCertificate ssh.Certificate) string {
+
+	if thisCertificate.ValidBefore == ssh.CertTimeInfinity {
+		return "forever"
+	}
+
+	return formatLifetime(getSshLifetime(thisCertificate))
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"github.com/spf13/cobra"
+)
+
+/*
+getDbConfig returns location of the database and remaining arguments.
+
+Database is described either by --ca-config file, or by the first argument.
+
+	'thisArgs' Given command line arguments.
+*/
+func getDbConfig(thisArgs []string) (stepdb.Config, []string, error) {
+
+	if len(config.caConfig) == 0 {
+		return stepdb.Config{Type: config.dbType.Value, DataSource: thisArgs[0]}, thisArgs[1:], nil
+	}
+
+	dbConfig, err := stepdb.LoadCAConfig(config.caConfig)
+	if err != nil {
+		return stepdb.Config{}, nil, err
+	}
+
+	// Explicit --db-type takes precedence over the type given in ca.json.
+	if config.dbType.Value != DB_AUTO || len(dbConfig.Type) == 0 {
+		dbConfig.Type = config.dbType.Value
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("database from %s: %#v", config.caConfig, dbConfig)
+	}
+
+	return dbConfig, thisArgs, nil
+}
+
+/*
+databaseArgs returns cobra validator expecting PATH followed by given number of arguments.
+PATH is not expected when --ca-config is given.
+
+	'thisCount' Number of arguments following PATH.
+*/
+func databaseArgs(thisCount int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(config.caConfig) > 0 {
+			return cobra.ExactArgs(thisCount)(cmd, args)
+		}
+		return cobra.ExactArgs(thisCount+1)(cmd, args)
+	}
+}
+
+/*
+openReader opens the step-ca database, honoring --read-only and --snapshot flags.
+
+	'thisDbConfig' Location of the database.
+*/
+func openReader(thisDbConfig stepdb.Config) (*stepdb.Reader, error) {
+
+	reader, err := stepdb.Open(thisDbConfig, stepdb.Options{ReadOnly: config.readOnly, Snapshot: config.snapshot})
+
+	switch {
+	case errors.Is(err, stepdb.ErrLocked) && config.readOnly:
+		return nil, fmt.Errorf("%w, probably a running step-ca, and read-only mode still needs a shared lock; "+
+			"use --snapshot to read from a copy", err)
+	case errors.Is(err, stepdb.ErrLocked):
+		return nil, fmt.Errorf("%w, probably a running step-ca, and neither --read-only nor --snapshot was given; "+
+			"use --snapshot to read from a copy", err)
+	case err != nil:
+		return nil, err
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("database type: %s", reader.Type())
+		if len(reader.SnapshotDir()) > 0 {
+			logInfo.Printf("snapshot taken into %s", reader.SnapshotDir())
+		}
+	}
+
+	return reader, nil
+}
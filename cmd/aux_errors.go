@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+)
+
+var recordErrors []error // Malformed records skipped in lenient mode.
+
+/*
+exitWithError logs given error and exits with given code. Caller's location is logged.
+
+	'thisCode' Exit code, see EXIT_* constants.
+	'thisErr' Error to be logged.
+*/
+func exitWithError(thisCode int, thisErr any) {
+	logError.Output(2, fmt.Sprintln(thisErr))
+	os.Exit(thisCode)
+}
+
+/*
+getBucketExitCode maps error of reading a bucket to the exit code. Malformed record, returned in strict mode, maps to
+EXIT_PARSE_ERROR.
+
+	'thisErr' Error returned while listing a bucket.
+*/
+func getBucketExitCode(thisErr error) int {
+	var recordErr *stepdb.RecordError
+	switch {
+	case errors.As(thisErr, &recordErr):
+		return EXIT_PARSE_ERROR
+	case errors.Is(thisErr, stepdb.ErrBucketNotFound) || errors.Is(thisErr, errNoRecords):
+		return EXIT_BUCKET_MISSING
+	}
+	return EXIT_FAILURE
+}
+
+/*
+handleRecordError remembers the record to be reported once done. In strict mode, the caller stops reading and fails
+with EXIT_PARSE_ERROR once the database is closed.
+
+	'thisErr' Error returned while reading a record.
+*/
+func handleRecordError(thisErr error) {
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("record skipped: %v", thisErr)
+	}
+
+	recordErrors = append(recordErrors, thisErr)
+}
+
+/*
+reportRecordErrors prints summary of records skipped in lenient mode.
+*/
+func reportRecordErrors() {
+
+	if len(recordErrors) == 0 {
+		return
+	}
+
+	summary := fmt.Sprintf("%d malformed record(s) skipped:", len(recordErrors))
+	for _, recordError := range recordErrors {
+		var recordErr *stepdb.RecordError
+		if errors.As(recordError, &recordErr) {
+			summary += fmt.Sprintf("\n  %s/%s: %v", recordErr.Bucket, recordErr.Key, recordErr.Err)
+		} else {
+			summary += fmt.Sprintf("\n  %v", recordError)
+		}
+	}
+
+	logWarning.Output(2, summary+"\n")
+}
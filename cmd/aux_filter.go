@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Record selection criteria as given by the user, before parsing.
+*/
+type tFilterArgs struct {
+	subject         string
+	san             string
+	provisioner     string
+	provisionerType string
+	serial          string
+	keyId           string
+	issuer          string
+	issuedAfter     string
+	issuedBefore    string
+	expiresAfter    string
+	expiresBefore   string
+	revokedAfter    string
+	revokedBefore   string
+}
+
+/*
+Record selection criteria, parsed. Nil or zero criterion matches everything.
+*/
+type tFilter struct {
+	subject         *regexp.Regexp
+	san             *regexp.Regexp
+	provisioner     *regexp.Regexp
+	provisionerType *regexp.Regexp
+	serial          *big.Int
+	keyId           *regexp.Regexp
+	issuer          *regexp.Regexp
+	issuedAfter     time.Time
+	issuedBefore    time.Time
+	expiresAfter    time.Time
+	expiresBefore   time.Time
+	revokedAfter    time.Time
+	revokedBefore   time.Time
+	validities      map[string]bool // Selected validities, nil selects all.
+}
+
+/*
+parse validates the criteria and compiles them.
+
+	'thisNow' Moment relative dates are counted from.
+*/
+func (thisArgs tFilterArgs) parse(thisNow time.Time) (tFilter, error) {
+
+	var (
+		filter tFilter
+		err    error
+	)
+
+	if filter.subject, err = compileRegexp(thisArgs.subject); err != nil {
+		return tFilter{}, fmt.Errorf("subject: %w", err)
+	}
+	if filter.issuer, err = compileRegexp(thisArgs.issuer); err != nil {
+		return tFilter{}, fmt.Errorf("issuer: %w", err)
+	}
+	filter.san = compileGlob(thisArgs.san)
+	filter.provisioner = compileGlob(thisArgs.provisioner)
+	filter.provisionerType = compileGlob(thisArgs.provisionerType)
+	filter.keyId = compileGlob(thisArgs.keyId)
+
+	if filter.serial, err = parseSerial(thisArgs.serial); err != nil {
+		return tFilter{}, err
+	}
+
+	for _, date := range []struct {
+		arg    string
+		target *time.Time
+	}{
+		{thisArgs.issuedAfter, &filter.issuedAfter},
+		{thisArgs.issuedBefore, &filter.issuedBefore},
+		{thisArgs.expiresAfter, &filter.expiresAfter},
+		{thisArgs.expiresBefore, &filter.expiresBefore},
+		{thisArgs.revokedAfter, &filter.revokedAfter},
+		{thisArgs.revokedBefore, &filter.revokedBefore},
+	} {
+		if *date.target, err = parseDate(date.arg, thisNow); err != nil {
+			return tFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
+
+/*
+matchX509 reports whether x509 certificate meets all criteria.
+
+	'thisX509' Certificate to be checked.
+*/
+func (thisFilter tFilter) matchX509(thisX509 tX509CertificateProvisionerRevocation) bool {
+
+	certificate := thisX509.X509Certificate
+
+	if thisFilter.validities != nil && !thisFilter.validities[thisX509.Validity] {
+		return false
+	}
+
+	if thisFilter.serial != nil && thisFilter.serial.Cmp(certificate.SerialNumber) != 0 {
+		return false
+	}
+
+	if thisFilter.subject != nil && !thisFilter.subject.MatchString(certificate.Subject.String()) {
+		return false
+	}
+
+	if thisFilter.issuer != nil && !thisFilter.issuer.MatchString(certificate.Issuer.String()) {
+		return false
+	}
+
+	if thisFilter.san != nil {
+		sans := append(append([]string{}, certificate.DNSNames...), certificate.EmailAddresses...)
+		for _, ipAddress := range certificate.IPAddresses {
+			sans = append(sans, ipAddress.String())
+		}
+		for _, uri := range certificate.URIs {
+			sans = append(sans, uri.String())
+		}
+		if !matchAny(thisFilter.san, sans) {
+			return false
+		}
+	}
+
+	if thisFilter.provisioner != nil && !thisFilter.provisioner.MatchString(thisX509.X509Provisioner.Name) {
+		return false
+	}
+
+	if thisFilter.provisionerType != nil && !thisFilter.provisionerType.MatchString(thisX509.X509Provisioner.Type) {
+		return false
+	}
+
+	return thisFilter.matchDates(certificate.NotBefore, certificate.NotAfter, thisX509.X509Revocation)
+}
+
+/*
+matchSsh reports whether ssh certificate meets all criteria.
+
+	'thisSsh' Certificate to be checked.
+*/
+func (thisFilter tFilter) matchSsh(thisSsh tSshCertificateWithRevocation) bool {
+
+	certificate := thisSsh.SshCertificate
+
+	if thisFilter.validities != nil && !thisFilter.validities[thisSsh.Validity] {
+		return false
+	}
+
+	if thisFilter.serial != nil && (!thisFilter.serial.IsUint64() || thisFilter.serial.Uint64() != certificate.Serial) {
+		return false
+	}
+
+	if thisFilter.subject != nil && !matchAny(thisFilter.subject, certificate.ValidPrincipals) {
+		return false
+	}
+
+	if thisFilter.san != nil && !matchAny(thisFilter.san, certificate.ValidPrincipals) {
+		return false
+	}
+
+	if thisFilter.keyId != nil && !thisFilter.keyId.MatchString(certificate.KeyId) {
+		return false
+	}
+
+	return thisFilter.matchDates(time.Unix(int64(certificate.ValidAfter), 0), getSshFinish(certificate),
+		thisSsh.SshCertificateRevocation)
+}
+
+/*
+matchDates reports whether certificate's dates fall into all date ranges.
+Certificates that are not revoked never match a revocation date range. Certificates that never expire are after any
+expiry date, hence before none.
+
+	'thisStart' Start of certificate's validity.
+	'thisFinish' End of certificate's validity, zero if it never expires.
+	'thisRevocation' Revocation of the certificate.
+*/
+func (thisFilter tFilter) matchDates(thisStart time.Time, thisFinish time.Time, thisRevocation tCertificateRevocation) bool {
+
+	isInRange := func(thisTime time.Time, thisAfter time.Time, thisBefore time.Time) bool {
+		return (thisAfter.IsZero() || thisTime.After(thisAfter)) && (thisBefore.IsZero() || thisTime.Before(thisBefore))
+	}
+
+	if !isInRange(thisStart, thisFilter.issuedAfter, thisFilter.issuedBefore) {
+		return false
+	}
+
+	if thisFinish.IsZero() {
+		if !thisFilter.expiresBefore.IsZero() {
+			return false
+		}
+	} else if !isInRange(thisFinish, thisFilter.expiresAfter, thisFilter.expiresBefore) {
+		return false
+	}
+
+	if !thisFilter.revokedAfter.IsZero() || !thisFilter.revokedBefore.IsZero() {
+		return thisRevocation.IsRevoked() && isInRange(thisRevocation.RevokedAt, thisFilter.revokedAfter, thisFilter.revokedBefore)
+	}
+
+	return true
+}
+
+/*
+matchAny reports whether any of given strings matches.
+
+	'thisRegexp' Compiled criterion.
+	'thisStrings' Strings to be checked.
+*/
+func matchAny(thisRegexp *regexp.Regexp, thisStrings []string) bool {
+	for _, thisString := range thisStrings {
+		if thisRegexp.MatchString(thisString) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+compileRegexp compiles given regular expression, nil if empty.
+
+	'thisExpr' Regular expression.
+*/
+func compileRegexp(thisExpr string) (*regexp.Regexp, error) {
+	if len(thisExpr) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(thisExpr)
+}
+
+/*
+compileGlob turns case-insensitive glob into anchored regular expression, nil if empty.
+'*' matches any run of characters, '?' matches single character.
+
+	'thisGlob' Glob pattern.
+*/
+func compileGlob(thisGlob string) *regexp.Regexp {
+
+	if len(thisGlob) == 0 {
+		return nil
+	}
+
+	expr := regexp.QuoteMeta(thisGlob)
+	expr = strings.ReplaceAll(expr, `\*`, `.*`)
+	expr = strings.ReplaceAll(expr, `\?`, `.`)
+
+	return regexp.MustCompile(`(?i)^` + expr + `$`)
+}
+
+/*
+parseSerial parses serial number given in decimal, or in hex when prefixed with 0x, containing hex letters or colons.
+
+	'thisSerial' Serial number, nil if empty.
+*/
+func parseSerial(thisSerial string) (*big.Int, error) {
+
+	if len(thisSerial) == 0 {
+		return nil, nil
+	}
+
+	digits, base := thisSerial, 10
+	switch {
+	case strings.HasPrefix(strings.ToLower(digits), "0x"):
+		digits, base = digits[2:], 16
+	case strings.ContainsAny(strings.ToLower(digits), "abcdef:"):
+		base = 16
+	}
+
+	serial, ok := new(big.Int).SetString(strings.ReplaceAll(digits, ":", ""), base)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid serial number", thisSerial)
+	}
+
+	return serial, nil
+}
+
+/*
+parseDate parses absolute date (2006-01-02 or RFC3339), or duration relative to now, e.g. 30d, -12h or 2w.
+
+	'thisDate' Date to be parsed, zero time if empty.
+	'thisNow' Moment relative dates are counted from.
+*/
+func parseDate(thisDate string, thisNow time.Time) (time.Time, error) {
+
+	if len(thisDate) == 0 {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse(time.DateOnly, thisDate); err == nil {
+		return date, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, thisDate); err == nil {
+		return date, nil
+	}
+
+	duration, err := parseDuration(thisDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date nor a duration", thisDate)
+	}
+
+	return thisNow.Add(duration), nil
+}
+
+/*
+parseDuration extends time.ParseDuration with days (d) and weeks (w) units, e.g. 30d or -2w.
+
+	'thisDuration' Duration to be parsed.
+*/
+func parseDuration(thisDuration string) (time.Duration, error) {
+
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+
+	if unit, ok := units[thisDuration[max(len(thisDuration)-1, 0):]]; ok {
+		count, err := strconv.ParseFloat(thisDuration[:len(thisDuration)-1], 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(count * float64(unit)), nil
+	}
+
+	return time.ParseDuration(thisDuration)
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseSerial(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		serial  string
+		want    string // Decimal, empty for nil.
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"decimal", "7000000000000000001", "7000000000000000001", false},
+		{"hex prefixed", "0x1F", "31", false},
+		{"hex prefixed upper", "0XFF", "255", false},
+		{"hex by letters", "ab", "171", false},
+		{"hex colon separated", "01:00", "256", false},
+		{"hex colon separated upper", "0A:0B", "2571", false},
+		{"not a number", "xyz", "", true},
+		{"hex prefix only", "0x", "", true},
+		{"decimal with garbage", "12g", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serial, err := parseSerial(test.serial)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseSerial(%q) error = %v, wantErr %v", test.serial, err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if len(test.want) == 0 {
+				if serial != nil {
+					t.Fatalf("parseSerial(%q) = %v, want nil", test.serial, serial)
+				}
+				return
+			}
+			want, _ := new(big.Int).SetString(test.want, 10)
+			if serial == nil || serial.Cmp(want) != 0 {
+				t.Errorf("parseSerial(%q) = %v, want %v", test.serial, serial, want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		date    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"empty", "", time.Time{}, false},
+		{"date only", "2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"rfc3339", "2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"rfc3339 offset", "2024-01-02T03:04:05+02:00", time.Date(2024, 1, 2, 1, 4, 5, 0, time.UTC), false},
+		{"days ahead", "30d", now.Add(30 * 24 * time.Hour), false},
+		{"hours back", "-12h", now.Add(-12 * time.Hour), false},
+		{"weeks ahead", "2w", now.Add(14 * 24 * time.Hour), false},
+		{"minutes", "90m", now.Add(90 * time.Minute), false},
+		{"invalid", "yesterday", time.Time{}, true},
+		{"invalid date", "2024-13-01", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			date, err := parseDate(test.date, now)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseDate(%q) error = %v, wantErr %v", test.date, err, test.wantErr)
+			}
+			if !date.Equal(test.want) {
+				t.Errorf("parseDate(%q) = %v, want %v", test.date, date, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		duration string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{"days", "30d", 30 * 24 * time.Hour, false},
+		{"fractional days", "1.5d", 36 * time.Hour, false},
+		{"negative weeks", "-2w", -14 * 24 * time.Hour, false},
+		{"hours", "12h", 12 * time.Hour, false},
+		{"compound", "1h30m", 90 * time.Minute, false},
+		{"seconds", "45s", 45 * time.Second, false},
+		{"empty", "", 0, true},
+		{"unit only", "d", 0, true},
+		{"no unit", "30", 0, true},
+		{"unknown unit", "3y", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			duration, err := parseDuration(test.duration)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseDuration(%q) error = %v, wantErr %v", test.duration, err, test.wantErr)
+			}
+			if duration != test.want {
+				t.Errorf("parseDuration(%q) = %v, want %v", test.duration, duration, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchX509(t *testing.T) {
+
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	uri, _ := url.Parse("spiffe://example.org/web")
+	x509Certificate := tX509CertificateProvisionerRevocation{
+		X509Certificate: x509.Certificate{
+			SerialNumber: big.NewInt(0x1234),
+			Subject:      pkix.Name{CommonName: "web.example.org"},
+			Issuer:       pkix.Name{CommonName: "Example Intermediate CA"},
+			DNSNames:     []string{"web.example.org", "www.example.org"},
+			IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+			URIs:         []*url.URL{uri},
+			NotBefore:    now.Add(-24 * time.Hour),
+			NotAfter:     now.Add(10 * 24 * time.Hour),
+		},
+		Validity:        VALID_STR,
+		X509Provisioner: tX509CertificateProvisioner{Name: "acme", Type: "ACME"},
+	}
+	revokedCertificate := x509Certificate
+	revokedCertificate.Validity = REVOKED_STR
+	revokedCertificate.X509Revocation = tCertificateRevocation{ProvisionerID: "acme/acme", RevokedAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name        string
+		args        tFilterArgs
+		validities  map[string]bool
+		certificate tX509CertificateProvisionerRevocation
+		want        bool
+	}{
+		{"no criteria", tFilterArgs{}, nil, x509Certificate, true},
+		{"validity selected", tFilterArgs{}, map[string]bool{VALID_STR: true}, x509Certificate, true},
+		{"validity not selected", tFilterArgs{}, map[string]bool{EXPIRED_STR: true}, x509Certificate, false},
+		{"serial decimal", tFilterArgs{serial: "4660"}, nil, x509Certificate, true},
+		{"serial hex", tFilterArgs{serial: "12:34"}, nil, x509Certificate, true},
+		{"serial other", tFilterArgs{serial: "1"}, nil, x509Certificate, false},
+		{"subject", tFilterArgs{subject: "CN=web\\..*"}, nil, x509Certificate, true},
+		{"subject unanchored", tFilterArgs{subject: "example"}, nil, x509Certificate, true},
+		{"subject other", tFilterArgs{subject: "^CN=www"}, nil, x509Certificate, false},
+		{"issuer", tFilterArgs{issuer: "Intermediate"}, nil, x509Certificate, true},
+		{"issuer case sensitive", tFilterArgs{issuer: "intermediate"}, nil, x509Certificate, false},
+		{"san dns name", tFilterArgs{san: "www.*"}, nil, x509Certificate, true},
+		{"san ip address", tFilterArgs{san: "10.0.0.*"}, nil, x509Certificate, true},
+		{"san uri", tFilterArgs{san: "spiffe://*"}, nil, x509Certificate, true},
+		{"san other", tFilterArgs{san: "mail.*"}, nil, x509Certificate, false},
+		{"provisioner", tFilterArgs{provisioner: "ac*"}, nil, x509Certificate, true},
+		{"provisioner other", tFilterArgs{provisioner: "jwk"}, nil, x509Certificate, false},
+		{"provisioner type", tFilterArgs{provisionerType: "acme"}, nil, x509Certificate, true},
+		{"key id ignored", tFilterArgs{keyId: "nobody"}, nil, x509Certificate, true},
+		{"issued after", tFilterArgs{issuedAfter: "-2d"}, nil, x509Certificate, true},
+		{"issued before", tFilterArgs{issuedAfter: "-2d", issuedBefore: "-2d"}, nil, x509Certificate, false},
+		{"expires before", tFilterArgs{expiresBefore: "30d"}, nil, x509Certificate, true},
+		{"expires after", tFilterArgs{expiresAfter: "30d"}, nil, x509Certificate, false},
+		{"revoked range, not revoked", tFilterArgs{revokedAfter: "-1d"}, nil, x509Certificate, false},
+		{"revoked range, revoked", tFilterArgs{revokedAfter: "-1d"}, nil, revokedCertificate, true},
+		{"revoked range, revoked earlier", tFilterArgs{revokedBefore: "-1d"}, nil, revokedCertificate, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := test.args.parse(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter.validities = test.validities
+			if match := filter.matchX509(test.certificate); match != test.want {
+				t.Errorf("matchX509() = %v, want %v", match, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchSsh(t *testing.T) {
+
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	sshCertificate := tSshCertificateWithRevocation{
+		SshCertificate: ssh.Certificate{
+			Serial:          7000000000000000001,
+			KeyId:           "alice@example.org",
+			ValidPrincipals: []string{"alice", "root"},
+			ValidAfter:      uint64(now.Add(-24 * time.Hour).Unix()),
+			ValidBefore:     uint64(now.Add(10 * 24 * time.Hour).Unix()),
+		},
+		Validity: VALID_STR,
+	}
+	foreverCertificate := sshCertificate
+	foreverCertificate.SshCertificate.ValidBefore = ssh.CertTimeInfinity
+	revokedCertificate := sshCertificate
+	revokedCertificate.Validity = REVOKED_STR
+	revokedCertificate.SshCertificateRevocation = tCertificateRevocation{ProvisionerID: "jwk/admin", RevokedAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name        string
+		args        tFilterArgs
+		validities  map[string]bool
+		certificate tSshCertificateWithRevocation
+		want        bool
+	}{
+		{"no criteria", tFilterArgs{}, nil, sshCertificate, true},
+		{"validity not selected", tFilterArgs{}, map[string]bool{REVOKED_STR: true}, sshCertificate, false},
+		{"serial", tFilterArgs{serial: "7000000000000000001"}, nil, sshCertificate, true},
+		{"serial other", tFilterArgs{serial: "7000000000000000002"}, nil, sshCertificate, false},
+		{"serial beyond uint64", tFilterArgs{serial: "0x10000000000000000"}, nil, sshCertificate, false},
+		{"subject principal", tFilterArgs{subject: "ro+t"}, nil, sshCertificate, true},
+		{"subject other", tFilterArgs{subject: "bob"}, nil, sshCertificate, false},
+		{"san principal", tFilterArgs{san: "ali*"}, nil, sshCertificate, true},
+		{"key id", tFilterArgs{keyId: "*@example.org"}, nil, sshCertificate, true},
+		{"key id other", tFilterArgs{keyId: "bob@*"}, nil, sshCertificate, false},
+		{"provisioner ignored", tFilterArgs{provisioner: "nobody"}, nil, sshCertificate, true},
+		{"issued before", tFilterArgs{issuedBefore: "-2d"}, nil, sshCertificate, false},
+		{"expires before", tFilterArgs{expiresBefore: "30d"}, nil, sshCertificate, true},
+		{"expires after", tFilterArgs{expiresAfter: "30d"}, nil, sshCertificate, false},
+		{"forever, no criteria", tFilterArgs{}, nil, foreverCertificate, true},
+		{"forever, expires before", tFilterArgs{expiresBefore: "30d"}, nil, foreverCertificate, false},
+		{"forever, expires before far", tFilterArgs{expiresBefore: "9999-12-31"}, nil, foreverCertificate, false},
+		{"forever, expires after", tFilterArgs{expiresAfter: "30d"}, nil, foreverCertificate, true},
+		{"forever, expires after far", tFilterArgs{expiresAfter: "9999-12-31"}, nil, foreverCertificate, true},
+		{"revoked range, not revoked", tFilterArgs{revokedBefore: "1d"}, nil, sshCertificate, false},
+		{"revoked range, revoked", tFilterArgs{revokedBefore: "1d"}, nil, revokedCertificate, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := test.args.parse(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter.validities = test.validities
+			if match := filter.matchSsh(test.certificate); match != test.want {
+				t.Errorf("matchSsh() = %v, want %v", match, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+loadCertificate reads x509 certificate, PEM or DER encoded. First certificate of PEM bundle is taken.
+
+	'thisPath' Location of the certificate.
+*/
+func loadCertificate(thisPath string) (*x509.Certificate, error) {
+
+	value, err := os.ReadFile(thisPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for rest := value; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			value = block.Bytes
+			break
+		}
+	}
+
+	certificate, err := x509.ParseCertificate(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", thisPath, err)
+	}
+
+	return certificate, nil
+}
+
+/*
+loadSigner reads private key, PEM or DER encoded, in PKCS #8, PKCS #1 or SEC 1 form.
+Legacy encrypted PEM, as written by step, is decrypted with password read from given file.
+
+	'thisPath' Location of the private key.
+	'thisPasswordPath' Location of the password, empty if the key is not encrypted.
+*/
+func loadSigner(thisPath string, thisPasswordPath string) (crypto.Signer, error) {
+
+	value, err := os.ReadFile(thisPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if block, _ := pem.Decode(value); block != nil {
+		value = block.Bytes
+
+		switch {
+		case block.Type == "ENCRYPTED PRIVATE KEY":
+			return nil, fmt.Errorf("%q: encrypted PKCS #8 keys are not supported, decrypt with: step crypto change-pass", thisPath)
+
+		case x509.IsEncryptedPEMBlock(block): // Legacy encryption is what step writes.
+			if len(thisPasswordPath) == 0 {
+				return nil, fmt.Errorf("%q is encrypted, password file required", thisPath)
+			}
+			password, err := os.ReadFile(thisPasswordPath)
+			if err != nil {
+				return nil, err
+			}
+			value, err = x509.DecryptPEMBlock(block, []byte(strings.TrimRight(string(password), "\r\n")))
+			if err != nil {
+				return nil, fmt.Errorf("decrypting %q: %w", thisPath, err)
+			}
+		}
+	}
+
+	var key any
+	if key, err = x509.ParsePKCS8PrivateKey(value); err != nil {
+		if key, err = x509.ParseECPrivateKey(value); err != nil {
+			if key, err = x509.ParsePKCS1PrivateKey(value); err != nil {
+				return nil, fmt.Errorf("parsing %q: unsupported private key", thisPath)
+			}
+		}
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+
+	return signer, nil
+}
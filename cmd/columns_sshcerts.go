@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"cmp"
 	"strconv"
 	"strings"
 	"time"
@@ -9,17 +10,20 @@ import (
 )
 
 type tSshColumn struct {
-	isShown         func(tConfig) bool
+	id              string // Stable identifier, used by --columns.
+	description     string
+	isShown         func(tConfig) bool // Shown by default, unless --columns says otherwise.
 	title           func() string
 	titleColor      color.Attribute
 	contentSource   func(tSshCertificateWithRevocation, tConfig) string
 	contentColor    func(tSshCertificateWithRevocation) color.Attribute
+	compare         func(a, b tSshCertificateWithRevocation) int // Compares for --sort, content compared as text if nil.
 	contentAlignMD  int
 	contentEscapeMD bool
 }
 
 /*
-getX509Columns defines look and content of table's emitted columns.
+getSshColumns defines look and content of table's emitted columns.
 */
 func getSshColumns() []tSshColumn {
 
@@ -28,6 +32,9 @@ func getSshColumns() []tSshColumn {
 	columns = append(columns,
 
 		tSshColumn{
+			id:          "serial",
+			description: "serial number, decimal",
+
 			isShown:    func(tc tConfig) bool { return tc.showSerial },
 			title:      func() string { return "Serial number" }, // Static title.
 			titleColor: color.Bold,
@@ -39,9 +46,16 @@ func getSshColumns() []tSshColumn {
 			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
 			contentAlignMD:  ALIGN_RIGHT,
 			contentEscapeMD: false,
+
+			compare: func(a, b tSshCertificateWithRevocation) int {
+				return cmp.Compare(a.SshCertificate.Serial, b.SshCertificate.Serial)
+			},
 		},
 
 		tSshColumn{
+			id:          "principals",
+			description: "valid principals",
+
 			isShown:    func(_ tConfig) bool { return true },        // Always shown.
 			title:      func() string { return "Valid principals" }, // Static title.
 			titleColor: color.Bold,
@@ -56,6 +70,9 @@ func getSshColumns() []tSshColumn {
 		},
 
 		tSshColumn{
+			id:          "type",
+			description: "user or host",
+
 			isShown:    func(tc tConfig) bool { return tc.showHostType },
 			title:      func() string { return "Type" }, // Static title.
 			titleColor: color.Bold,
@@ -72,6 +89,9 @@ func getSshColumns() []tSshColumn {
 		},
 
 		tSshColumn{
+			id:          "keyid",
+			description: "key id",
+
 			isShown:    func(tc tConfig) bool { return tc.showKeyId },
 			title:      func() string { return "Key ID" }, // Static title.
 			titleColor: color.Bold,
@@ -84,6 +104,9 @@ func getSshColumns() []tSshColumn {
 		},
 
 		tSshColumn{
+			id:          "start",
+			description: "start of validity",
+
 			isShown:    func(_ tConfig) bool { return true }, // Always shown.
 			title:      func() string { return "Start" },     // Static title.
 			titleColor: color.Bold,
@@ -99,9 +122,16 @@ func getSshColumns() []tSshColumn {
 			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgHiBlack }, // Static color.
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
+
+			compare: func(a, b tSshCertificateWithRevocation) int {
+				return cmp.Compare(a.SshCertificate.ValidAfter, b.SshCertificate.ValidAfter)
+			},
 		},
 
 		tSshColumn{
+			id:          "finish",
+			description: "finish of validity",
+
 			isShown:    func(_ tConfig) bool { return true }, // Always shown.
 			title:      func() string { return "Finish" },    // Static title.
 			titleColor: color.Bold,
@@ -117,9 +147,16 @@ func getSshColumns() []tSshColumn {
 			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgHiBlack }, // Static color.
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
+
+			compare: func(a, b tSshCertificateWithRevocation) int {
+				return cmp.Compare(a.SshCertificate.ValidBefore, b.SshCertificate.ValidBefore)
+			},
 		},
 
 		tSshColumn{
+			id:          "revokedat",
+			description: "moment of revocation",
+
 			isShown:    func(tc tConfig) bool { return tc.showRevoked }, // Always shown.
 			title:      func() string { return "Revoked at" },           // Static title.
 			titleColor: color.Bold,
@@ -139,9 +176,16 @@ func getSshColumns() []tSshColumn {
 			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgHiBlack }, // Static color.
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
+
+			compare: func(a, b tSshCertificateWithRevocation) int {
+				return a.SshCertificateRevocation.RevokedAt.Compare(b.SshCertificateRevocation.RevokedAt)
+			},
 		},
 
 		tSshColumn{
+			id:          "validity",
+			description: "valid, expired or revoked",
+
 			isShown:    func(_ tConfig) bool { return true }, // Always shown.
 			title:      func() string { return "Validity" },  // Static title.
 			titleColor: color.Bold,
@@ -156,11 +200,262 @@ func getSshColumns() []tSshColumn {
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
 		},
+
+		tSshColumn{
+			id:          "keytype",
+			description: "certified key algorithm, with key size or curve",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Key type" },   // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return getSshPublicKeyStr(x.SshCertificate.Key)
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "fingerprint",
+			description: "SHA256 fingerprint of certified key",
+
+			isShown:    func(_ tConfig) bool { return false },      // Shown with --columns only.
+			title:      func() string { return "Key fingerprint" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return getSshFingerprint(x.SshCertificate.Key)
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "cafingerprint",
+			description: "SHA256 fingerprint of signing CA key",
+
+			isShown:    func(_ tConfig) bool { return false },     // Shown with --columns only.
+			title:      func() string { return "CA fingerprint" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return getSshFingerprint(x.SshCertificate.SignatureKey)
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "criticaloptions",
+			description: "critical options, e.g. force-command, source-address",
+
+			isShown:    func(_ tConfig) bool { return false },       // Shown with --columns only.
+			title:      func() string { return "Critical options" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return strings.Join(getSshOptions(x.SshCertificate.CriticalOptions), ", ")
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "extensions",
+			description: "extensions, e.g. permit-pty, permit-agent-forwarding",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Extensions" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return strings.Join(getSshOptions(x.SshCertificate.Extensions), ", ")
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "lifetime",
+			description: "total lifetime, from start to finish",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Lifetime" },   // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return getSshLifetimeStr(x.SshCertificate)
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_RIGHT,
+			contentEscapeMD: true,
+
+			compare: func(a, b tSshCertificateWithRevocation) int {
+				return cmp.Compare(getSshLifetime(a.SshCertificate), getSshLifetime(b.SshCertificate))
+			},
+		},
+
+		tSshColumn{
+			id:          "reasoncode",
+			description: "revocation reason code, with RFC 5280 name",
+
+			isShown:    func(_ tConfig) bool { return false },  // Shown with --columns only.
+			title:      func() string { return "Reason code" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return getRevocationReasonCodeStr(x.SshCertificateRevocation)
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+
+			compare: func(a, b tSshCertificateWithRevocation) int {
+				return cmp.Compare(getRevocationSortCode(a.SshCertificateRevocation), getRevocationSortCode(b.SshCertificateRevocation))
+			},
+		},
+
+		tSshColumn{
+			id:          "reason",
+			description: "revocation reason, free text",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Reason" },     // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return x.SshCertificateRevocation.Reason
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "revokedby",
+			description: "revoking provisioner",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Revoked by" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return x.SshCertificateRevocation.ProvisionerID
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "revocationmethod",
+			description: "revocation method: ACME, mTLS or token",
+
+			isShown:    func(_ tConfig) bool { return false },        // Shown with --columns only.
+			title:      func() string { return "Revocation method" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return getRevocationMethod(x.SshCertificateRevocation)
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tSshColumn{
+			id:          "tokenid",
+			description: "id of token revocation was requested with",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Token ID" },   // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tSshCertificateWithRevocation, _ tConfig) string {
+				return x.SshCertificateRevocation.TokenID
+			},
+
+			contentColor:    func(_ tSshCertificateWithRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
 	)
 
 	return columns
 }
 
+/*
+getShownSshColumns returns columns to be emitted, as selected and ordered by --columns, or those shown by default.
+*/
+func getShownSshColumns() []tSshColumn {
+
+	columns := getSshColumns()
+
+	ids, _, defaults := getSshColumnIds(columns)
+	indexes, err := selectColumns(ids, defaults, config.columns)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	var shownColumns []tSshColumn
+	for _, index := range indexes {
+		shownColumns = append(shownColumns, columns[index])
+	}
+
+	return shownColumns
+}
+
+/*
+getSshSortKeys resolves given --sort against ids of columns.
+
+	'thisSpec' Value of --sort, e.g. type,-finish,principals.
+*/
+func getSshSortKeys(thisSpec string) ([]tSortKey, error) {
+	ids, _, _ := getSshColumnIds(getSshColumns())
+	return parseSortKeys(ids, thisSpec)
+}
+
+/*
+emitSshColumnList prints ids of columns, for --list-columns.
+*/
+func emitSshColumnList() {
+	emitColumnList(getSshColumnIds(getSshColumns()))
+}
+
+/*
+getSshColumnIds returns ids, descriptions and default visibility of given columns.
+
+	'thisColumns' Columns, in natural order.
+*/
+func getSshColumnIds(thisColumns []tSshColumn) ([]string, []string, []bool) {
+
+	var ids, descriptions []string
+	var defaults []bool
+	for _, column := range thisColumns {
+		ids = append(ids, column.id)
+		descriptions = append(descriptions, column.description)
+		defaults = append(defaults, column.isShown(config))
+	}
+
+	return ids, descriptions, defaults
+}
+
 /*
 getCertType maps given CertType to string to be displayed.
 */
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"cmp"
+	"encoding/hex"
+	"strconv"
 	"strings"
 	"time"
 
@@ -8,11 +11,14 @@ import (
 )
 
 type tX509Column struct {
-	isShown         func(tConfig) bool
+	id              string // Stable identifier, used by --columns.
+	description     string
+	isShown         func(tConfig) bool // Shown by default, unless --columns says otherwise.
 	title           func() string
 	titleColor      color.Attribute
 	contentSource   func(tX509CertificateProvisionerRevocation, tConfig) string
 	contentColor    func(tX509CertificateProvisionerRevocation) color.Attribute
+	compare         func(a, b tX509CertificateProvisionerRevocation) int // Compares for --sort, content compared as text if nil.
 	contentAlignMD  int
 	contentEscapeMD bool
 }
@@ -27,6 +33,9 @@ func getX509Columns() []tX509Column {
 	columns = append(columns,
 
 		tX509Column{
+			id:          "serial",
+			description: "serial number, decimal",
+
 			isShown:    func(tc tConfig) bool { return tc.showSerial },
 			title:      func() string { return "Serial number" }, // Static title.
 			titleColor: color.Bold,
@@ -38,9 +47,16 @@ func getX509Columns() []tX509Column {
 			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
 			contentAlignMD:  ALIGN_RIGHT,
 			contentEscapeMD: false,
+
+			compare: func(a, b tX509CertificateProvisionerRevocation) int {
+				return a.X509Certificate.SerialNumber.Cmp(b.X509Certificate.SerialNumber)
+			},
 		},
 
 		tX509Column{
+			id:          "subject",
+			description: "subject distinguished name",
+
 			isShown:    func(_ tConfig) bool { return true }, // Always shown.
 			title:      func() string { return "Subject" },   // Static title.
 			titleColor: color.Bold,
@@ -55,6 +71,9 @@ func getX509Columns() []tX509Column {
 		},
 
 		tX509Column{
+			id:          "issuer",
+			description: "common name of issuer",
+
 			isShown:    func(tc tConfig) bool { return tc.showIssuer },
 			title:      func() string { return "Issuer" }, // Static title.
 			titleColor: color.Bold,
@@ -69,6 +88,9 @@ func getX509Columns() []tX509Column {
 		},
 
 		tX509Column{
+			id:          "dnsnames",
+			description: "dns names",
+
 			isShown:    func(tc tConfig) bool { return tc.showDNSNames },
 			title:      func() string { return "DNS names" }, // Static title.
 			titleColor: color.Bold,
@@ -83,6 +105,9 @@ func getX509Columns() []tX509Column {
 		},
 
 		tX509Column{
+			id:          "emailaddresses",
+			description: "email addresses",
+
 			isShown:    func(tc tConfig) bool { return tc.showEmailAddresses },
 			title:      func() string { return "Email addresses" }, // Static title.
 			titleColor: color.Bold,
@@ -97,6 +122,9 @@ func getX509Columns() []tX509Column {
 		},
 
 		tX509Column{
+			id:          "ipaddresses",
+			description: "ip addresses",
+
 			isShown:    func(tc tConfig) bool { return tc.showIPAddresses },
 			title:      func() string { return "IP addresses" }, // Static title.
 			titleColor: color.Bold,
@@ -115,6 +143,9 @@ func getX509Columns() []tX509Column {
 		},
 
 		tX509Column{
+			id:          "uris",
+			description: "uris",
+
 			isShown:    func(tc tConfig) bool { return tc.showURIs },
 			title:      func() string { return "URIs" }, // Static title.
 			titleColor: color.Bold,
@@ -133,6 +164,9 @@ func getX509Columns() []tX509Column {
 		},
 
 		tX509Column{
+			id:          "crl",
+			description: "crl distribution points",
+
 			isShown:    func(tc tConfig) bool { return tc.showCrl },
 			title:      func() string { return "CRL distribution points" }, // Static title.
 			titleColor: color.Bold,
@@ -147,6 +181,9 @@ func getX509Columns() []tX509Column {
 		},
 
 		tX509Column{
+			id:          "provisioner",
+			description: "provisioner type and name",
+
 			isShown:    func(tc tConfig) bool { return tc.showProvisioner },
 			title:      func() string { return "Provisioner" }, // Static title.
 			titleColor: color.Bold,
@@ -158,9 +195,19 @@ func getX509Columns() []tX509Column {
 			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
+
+			compare: func(a, b tX509CertificateProvisionerRevocation) int {
+				if result := strings.Compare(a.X509Provisioner.Type, b.X509Provisioner.Type); result != 0 {
+					return result
+				}
+				return strings.Compare(a.X509Provisioner.Name, b.X509Provisioner.Name)
+			},
 		},
 
 		tX509Column{
+			id:          "start",
+			description: "start of validity",
+
 			isShown:    func(_ tConfig) bool { return true }, // Always shown.
 			title:      func() string { return "Start" },     // Static title.
 			titleColor: color.Bold,
@@ -176,9 +223,16 @@ func getX509Columns() []tX509Column {
 			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgHiBlack }, // Static color.
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
+
+			compare: func(a, b tX509CertificateProvisionerRevocation) int {
+				return a.X509Certificate.NotBefore.Compare(b.X509Certificate.NotBefore)
+			},
 		},
 
 		tX509Column{
+			id:          "finish",
+			description: "finish of validity",
+
 			isShown:    func(_ tConfig) bool { return true }, // Always shown.
 			title:      func() string { return "Finish" },    // Static title.
 			titleColor: color.Bold,
@@ -194,9 +248,16 @@ func getX509Columns() []tX509Column {
 			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgHiBlack }, // Static color.
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
+
+			compare: func(a, b tX509CertificateProvisionerRevocation) int {
+				return a.X509Certificate.NotAfter.Compare(b.X509Certificate.NotAfter)
+			},
 		},
 
 		tX509Column{
+			id:          "revokedat",
+			description: "moment of revocation",
+
 			isShown:    func(tc tConfig) bool { return tc.showRevoked }, // Always shown.
 			title:      func() string { return "Revoked at" },           // Static title.
 			titleColor: color.Bold,
@@ -216,9 +277,16 @@ func getX509Columns() []tX509Column {
 			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgHiBlack }, // Static color.
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
+
+			compare: func(a, b tX509CertificateProvisionerRevocation) int {
+				return a.X509Revocation.RevokedAt.Compare(b.X509Revocation.RevokedAt)
+			},
 		},
 
 		tX509Column{
+			id:          "validity",
+			description: "valid, expired or revoked",
+
 			isShown:    func(_ tConfig) bool { return true }, // Always shown.
 			title:      func() string { return "Validity" },  // Static title.
 			titleColor: color.Bold,
@@ -233,7 +301,330 @@ func getX509Columns() []tX509Column {
 			contentAlignMD:  ALIGN_LEFT,
 			contentEscapeMD: true,
 		},
+
+		tX509Column{
+			id:          "keyalgorithm",
+			description: "public key algorithm, with key size or curve",
+
+			isShown:    func(_ tConfig) bool { return false },    // Shown with --columns only.
+			title:      func() string { return "Key algorithm" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return getPublicKeyStr(x.X509Certificate.PublicKey)
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "signaturealgorithm",
+			description: "signature algorithm",
+
+			isShown:    func(_ tConfig) bool { return false },          // Shown with --columns only.
+			title:      func() string { return "Signature algorithm" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return x.X509Certificate.SignatureAlgorithm.String()
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "fingerprint",
+			description: "SHA-256 fingerprint, hex",
+
+			isShown:    func(_ tConfig) bool { return false },          // Shown with --columns only.
+			title:      func() string { return "SHA-256 fingerprint" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return getSha256Hex(x.X509Certificate.Raw)
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: false,
+		},
+
+		tX509Column{
+			id:          "ski",
+			description: "subject key identifier, hex",
+
+			isShown:    func(_ tConfig) bool { return false },     // Shown with --columns only.
+			title:      func() string { return "Subject key ID" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return hex.EncodeToString(x.X509Certificate.SubjectKeyId)
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: false,
+		},
+
+		tX509Column{
+			id:          "aki",
+			description: "authority key identifier, hex",
+
+			isShown:    func(_ tConfig) bool { return false },       // Shown with --columns only.
+			title:      func() string { return "Authority key ID" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return hex.EncodeToString(x.X509Certificate.AuthorityKeyId)
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: false,
+		},
+
+		tX509Column{
+			id:          "keyusage",
+			description: "key usage names",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Key usage" },  // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return strings.Join(getKeyUsageNames(x.X509Certificate), ", ")
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "extkeyusage",
+			description: "extended key usage names",
+
+			isShown:    func(_ tConfig) bool { return false },         // Shown with --columns only.
+			title:      func() string { return "Extended key usage" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return strings.Join(getExtKeyUsageNames(x.X509Certificate), ", ")
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "isca",
+			description: "certificate authority, true or false",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "CA" },         // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return strconv.FormatBool(x.X509Certificate.IsCA)
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "policies",
+			description: "certificate policy OIDs",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Policies" },   // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				var policies []string
+				for _, policy := range x.X509Certificate.PolicyIdentifiers {
+					policies = append(policies, policy.String())
+				}
+				return strings.Join(policies, ", ")
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "lifetime",
+			description: "total lifetime, from start to finish",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Lifetime" },   // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return formatLifetime(x.X509Certificate.NotAfter.Sub(x.X509Certificate.NotBefore))
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_RIGHT,
+			contentEscapeMD: true,
+
+			compare: func(a, b tX509CertificateProvisionerRevocation) int {
+				return cmp.Compare(a.X509Certificate.NotAfter.Sub(a.X509Certificate.NotBefore), b.X509Certificate.NotAfter.Sub(b.X509Certificate.NotBefore))
+			},
+		},
+
+		tX509Column{
+			id:          "reasoncode",
+			description: "revocation reason code, with RFC 5280 name",
+
+			isShown:    func(_ tConfig) bool { return false },  // Shown with --columns only.
+			title:      func() string { return "Reason code" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return getRevocationReasonCodeStr(x.X509Revocation)
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+
+			compare: func(a, b tX509CertificateProvisionerRevocation) int {
+				return cmp.Compare(getRevocationSortCode(a.X509Revocation), getRevocationSortCode(b.X509Revocation))
+			},
+		},
+
+		tX509Column{
+			id:          "reason",
+			description: "revocation reason, free text",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Reason" },     // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return x.X509Revocation.Reason
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "revokedby",
+			description: "revoking provisioner",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Revoked by" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return x.X509Revocation.ProvisionerID
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "revocationmethod",
+			description: "revocation method: ACME, mTLS or token",
+
+			isShown:    func(_ tConfig) bool { return false },        // Shown with --columns only.
+			title:      func() string { return "Revocation method" }, // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return getRevocationMethod(x.X509Revocation)
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
+
+		tX509Column{
+			id:          "tokenid",
+			description: "id of token revocation was requested with",
+
+			isShown:    func(_ tConfig) bool { return false }, // Shown with --columns only.
+			title:      func() string { return "Token ID" },   // Static title.
+			titleColor: color.Bold,
+
+			contentSource: func(x tX509CertificateProvisionerRevocation, _ tConfig) string {
+				return x.X509Revocation.TokenID
+			},
+
+			contentColor:    func(_ tX509CertificateProvisionerRevocation) color.Attribute { return color.FgWhite }, // Static color.
+			contentAlignMD:  ALIGN_LEFT,
+			contentEscapeMD: true,
+		},
 	)
 
 	return columns
 }
+
+/*
+getShownX509Columns returns columns to be emitted, as selected and ordered by --columns, or those shown by default.
+*/
+func getShownX509Columns() []tX509Column {
+
+	columns := getX509Columns()
+
+	ids, _, defaults := getX509ColumnIds(columns)
+	indexes, err := selectColumns(ids, defaults, config.columns)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	var shownColumns []tX509Column
+	for _, index := range indexes {
+		shownColumns = append(shownColumns, columns[index])
+	}
+
+	return shownColumns
+}
+
+/*
+getX509SortKeys resolves given --sort against ids of columns.
+
+	'thisSpec' Value of --sort, e.g. provisioner,-finish,subject.
+*/
+func getX509SortKeys(thisSpec string) ([]tSortKey, error) {
+	ids, _, _ := getX509ColumnIds(getX509Columns())
+	return parseSortKeys(ids, thisSpec)
+}
+
+/*
+emitX509ColumnList prints ids of columns, for --list-columns.
+*/
+func emitX509ColumnList() {
+	emitColumnList(getX509ColumnIds(getX509Columns()))
+}
+
+/*
+getX509ColumnIds returns ids, descriptions and default visibility of given columns.
+
+	'thisColumns' Columns, in natural order.
+*/
+func getX509ColumnIds(thisColumns []tX509Column) ([]string, []string, []bool) {
+
+	var ids, descriptions []string
+	var defaults []bool
+	for _, column := range thisColumns {
+		ids = append(ids, column.id)
+		descriptions = append(descriptions, column.description)
+		defaults = append(defaults, column.isShown(config))
+	}
+
+	return ids, descriptions, defaults
+}
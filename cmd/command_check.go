@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the shell command.
+var checkCmd = &cobra.Command{
+	Long: `
+Check expiry of valid certificates out of the badger database of step-ca.
+
+Prints one-line status with perfdata and exits with 0 (OK), 1 (WARNING),
+2 (CRITICAL) or 3 (UNKNOWN), as monitoring plugins of Nagios or Icinga do.`,
+
+	Short:                 "Check expiry of certificates.",
+	DisableFlagsInUseLine: true,
+	Use: `check <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger check ./db
+  step-badger check ./db --certs x509 --warning 14d --critical 3d --match-provisioner acme
+  step-badger check --ca-config /etc/step-ca/config/ca.json --read-only`,
+
+	// Wrong arguments are UNKNOWN, as any other misconfiguration.
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := databaseArgs(0)(cmd, args); err != nil {
+			exitCheckUnknown(err)
+		}
+		return nil
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		checkMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	// Hide help command.
+	checkCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	checkCmd.Flags().SortFlags = false
+
+	// Wrong flags are UNKNOWN, as any other misconfiguration.
+	checkCmd.SetFlagErrorFunc(func(_ *cobra.Command, thisErr error) error {
+		exitCheckUnknown(thisErr)
+		return nil
+	})
+
+	// Thresholds.
+	checkCmd.Flags().StringVarP(&config.checkWarning, "warning", "w", "30d", "warning, when valid certificate expires within")
+	checkCmd.Flags().StringVarP(&config.checkCritical, "critical", "c", "7d", "critical, when valid certificate expires within")
+
+	// Records selection criteria.
+	checkCmd.Flags().Var(config.checkCerts, "certs", "certificates checked: "+CERTS_ALL+"|"+CERTS_X509+"|"+CERTS_SSH)
+	checkCmd.Flags().StringVar(&config.filterArgs.subject, "match-subject", "", "subject regex, principal regex for ssh")
+	checkCmd.Flags().StringVar(&config.filterArgs.san, "match-san", "", "dns name, ip address, email address or uri glob, principal glob for ssh")
+	checkCmd.Flags().StringVar(&config.filterArgs.provisioner, "match-provisioner", "", "provisioner name glob, x509 only, implies --certs x509")
+	checkCmd.Flags().StringVar(&config.filterArgs.keyId, "match-keyid", "", "key id glob, ssh only, implies --certs ssh")
+}
+
+/*
+Check main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func checkMain(args []string) {
+
+	if err := getLogginglevelErr(); err != nil {
+		exitCheckUnknown(err)
+	}
+	checkLogginglevel(args)
+
+	now := time.Now()
+
+	// Parse thresholds.
+	warning, err := parseDuration(config.checkWarning)
+	if err != nil {
+		exitCheckUnknown(fmt.Errorf("warning: %w", err))
+	}
+	critical, err := parseDuration(config.checkCritical)
+	if err != nil {
+		exitCheckUnknown(fmt.Errorf("critical: %w", err))
+	}
+	if critical > warning {
+		exitCheckUnknown(errors.New("critical threshold exceeds warning threshold"))
+	}
+
+	// Certificates checked follow filters applying to one kind only.
+	hasX509Filter, hasSshFilter := len(config.filterArgs.provisioner) > 0, len(config.filterArgs.keyId) > 0
+	switch {
+	case hasX509Filter && hasSshFilter:
+		exitCheckUnknown(errors.New("--match-provisioner applies to x509 only, --match-keyid to ssh only"))
+	case hasX509Filter && config.checkCerts.Value == CERTS_SSH:
+		exitCheckUnknown(errors.New("--match-provisioner applies to x509 only, not with --certs " + CERTS_SSH))
+	case hasSshFilter && config.checkCerts.Value == CERTS_X509:
+		exitCheckUnknown(errors.New("--match-keyid applies to ssh only, not with --certs " + CERTS_X509))
+	case hasX509Filter:
+		config.checkCerts.Value = CERTS_X509
+	case hasSshFilter:
+		config.checkCerts.Value = CERTS_SSH
+	}
+
+	// Parse record selection criteria.
+	filter, err := config.filterArgs.parse(now)
+	if err != nil {
+		exitCheckUnknown(err)
+	}
+	filter.validities = getSelectedValidities(true, false, false)
+
+	// Open the database.
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitCheckUnknown(err)
+	}
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitCheckUnknown(err)
+	}
+
+	// Malformed records do not stop the check, unless in strict mode.
+	collectRecordError := func(thisErr error) {
+		recordErrors = append(recordErrors, thisErr)
+	}
+
+	var checkedCertificates []tCheckedCertificate
+
+	// Get valid x509 certificates.
+	if config.checkCerts.Value != CERTS_SSH {
+		x509Certificates, err := loadX509Certificates(reader, collectRecordError)
+		if err != nil && !isBucketTolerated(err) {
+			reader.Close()
+			exitCheckUnknown(err)
+		}
+		for _, x509Certificate := range x509Certificates {
+			if filter.matchX509(x509Certificate) {
+				checkedCertificates = append(checkedCertificates, tCheckedCertificate{
+					Kind:   CERTS_X509,
+					Serial: x509Certificate.X509Certificate.SerialNumber.String(),
+					Name:   x509Certificate.X509Certificate.Subject.String(),
+					Finish: x509Certificate.X509Certificate.NotAfter,
+				})
+			}
+		}
+	}
+
+	// Get valid ssh certificates, those never expiring are never due.
+	if config.checkCerts.Value != CERTS_X509 {
+		sshCertificates, err := loadSshCertificates(reader, collectRecordError)
+		if err != nil && !isBucketTolerated(err) {
+			reader.Close()
+			exitCheckUnknown(err)
+		}
+		for _, sshCertificate := range sshCertificates {
+			finish := getSshFinish(sshCertificate.SshCertificate)
+			if filter.matchSsh(sshCertificate) && !finish.IsZero() {
+				checkedCertificates = append(checkedCertificates, tCheckedCertificate{
+					Kind:   CERTS_SSH,
+					Serial: strconv.FormatUint(sshCertificate.SshCertificate.Serial, 10),
+					Name:   strings.Join(sshCertificate.SshCertificate.ValidPrincipals, ","),
+					Finish: finish,
+				})
+			}
+		}
+	}
+
+	// Close the database.
+	if err = reader.Close(); err != nil {
+		exitCheckUnknown(err)
+	}
+
+	// Evaluate against thresholds, soonest expiring first.
+	sort.SliceStable(checkedCertificates, func(i, j int) bool {
+		return checkedCertificates[i].Finish.Before(checkedCertificates[j].Finish)
+	})
+
+	counts := map[int]int{}
+	status := CHECK_OK
+	for i := range checkedCertificates {
+		switch remaining := checkedCertificates[i].Finish.Sub(now); {
+		case remaining <= critical:
+			checkedCertificates[i].Status = CHECK_CRITICAL
+		case remaining <= warning:
+			checkedCertificates[i].Status = CHECK_WARNING
+		default:
+			checkedCertificates[i].Status = CHECK_OK
+		}
+		counts[checkedCertificates[i].Status]++
+		status = max(status, checkedCertificates[i].Status)
+	}
+
+	emitCheck(status, counts, checkedCertificates, now, warning, critical)
+
+	os.Exit(status)
+}
+
+/*
+emitCheck prints one-line status with perfdata, followed by a line per certificate not being OK.
+
+	'thisStatus' Overall status, one of CHECK_* codes.
+	'thisCounts' Number of certificates per status.
+	'thisCheckedCertificates' Evaluated certificates, soonest expiring first.
+	'thisNow' Moment of evaluation.
+	'thisWarning' Warning threshold.
+	'thisCritical' Critical threshold.
+*/
+func emitCheck(thisStatus int, thisCounts map[int]int, thisCheckedCertificates []tCheckedCertificate,
+	thisNow time.Time, thisWarning time.Duration, thisCritical time.Duration) {
+
+	toDays := func(thisDuration time.Duration) float64 {
+		return math.Round(thisDuration.Hours()/24*100) / 100
+	}
+
+	text := fmt.Sprintf("%d critical, %d warning, %d ok", thisCounts[CHECK_CRITICAL], thisCounts[CHECK_WARNING], thisCounts[CHECK_OK])
+	if len(thisCheckedCertificates) > 0 {
+		soonest := thisCheckedCertificates[0]
+		text += fmt.Sprintf("; soonest %s %s %q expires in %.2f days",
+			soonest.Kind, soonest.Serial, soonest.Name, toDays(soonest.Finish.Sub(thisNow)))
+	}
+	if len(recordErrors) > 0 {
+		text += fmt.Sprintf("; %d malformed record(s) skipped", len(recordErrors))
+	}
+
+	perfData := fmt.Sprintf("total=%d;;;0 ok=%d;;;0 warning=%d;;;0 critical=%d;;;0 malformed=%d;;;0",
+		len(thisCheckedCertificates), thisCounts[CHECK_OK], thisCounts[CHECK_WARNING], thisCounts[CHECK_CRITICAL],
+		len(recordErrors))
+	if len(thisCheckedCertificates) > 0 {
+		perfData += fmt.Sprintf(" min_expiry_days=%.2f;%g:;%g:",
+			toDays(thisCheckedCertificates[0].Finish.Sub(thisNow)), toDays(thisWarning), toDays(thisCritical))
+	}
+
+	fmt.Printf("STEP-BADGER %s - %s | %s\n", getCheckStatusStr()[thisStatus], text, perfData)
+
+	// Long output.
+	for _, checkedCertificate := range thisCheckedCertificates {
+		if checkedCertificate.Status != CHECK_OK {
+			fmt.Printf("%s: %s %s %q expires %s\n", getCheckStatusStr()[checkedCertificate.Status],
+				checkedCertificate.Kind, checkedCertificate.Serial, checkedCertificate.Name,
+				checkedCertificate.Finish.UTC().Format(time.RFC3339))
+		}
+	}
+}
+
+/*
+exitCheckUnknown prints UNKNOWN status with given error and exits accordingly.
+
+	'thisErr' Error that prevented the check.
+*/
+func exitCheckUnknown(thisErr error) {
+	fmt.Printf("STEP-BADGER %s - %v\n", getCheckStatusStr()[CHECK_UNKNOWN], thisErr)
+	os.Exit(CHECK_UNKNOWN)
+}
+
+/*
+isBucketTolerated reports whether error of reading a bucket just means there are no certificates to check.
+Missing bucket is tolerated only when all kinds of certificates are checked, e.g. CA without ssh.
+
+	'thisErr' Error returned while reading a bucket.
+*/
+func isBucketTolerated(thisErr error) bool {
+	return errors.Is(thisErr, errNoRecords) ||
+		(config.checkCerts.Value == CERTS_ALL && errors.Is(thisErr, stepdb.ErrBucketNotFound))
+}
@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"github.com/smallstep/nosql/database"
+	"github.com/spf13/cobra"
+)
+
+// crlCmd represents the shell command.
+var crlCmd = &cobra.Command{
+	Long: `
+Generate RFC 5280 certificate revocation list out of the badger database of step-ca.
+
+Every entry of revoked_x509_certs bucket is listed with its reason code, except
+those of certificates issued by another CA. The list is signed with given CA
+certificate and key.`,
+
+	Short:                 "Generate x509 CRL.",
+	DisableFlagsInUseLine: true,
+	Use: `crl <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger crl ./db --ca-cert intermediate_ca.crt --ca-key intermediate_ca_key --ca-key-password-file pass.txt
+  step-badger crl ./db --ca-cert ca.crt --ca-key ca.key --omit-expired --next-update 7d --out ca.crl --format der`,
+
+	Args: databaseArgs(0),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		crlMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(crlCmd)
+
+	// Hide help command.
+	crlCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	crlCmd.Flags().SortFlags = false
+
+	// Signer.
+	crlCmd.Flags().StringVar(&config.caCert, "ca-cert", "", "CA certificate signing the list, PEM or DER")
+	crlCmd.Flags().StringVar(&config.caKey, "ca-key", "", "private key of the CA certificate, PEM or DER")
+	crlCmd.Flags().StringVar(&config.caKeyPasswordFile, "ca-key-password-file", "", "file holding password of encrypted private key")
+	crlCmd.MarkFlagRequired("ca-cert")
+	crlCmd.MarkFlagRequired("ca-key")
+
+	// Content of the list.
+	crlCmd.Flags().StringVar(&config.crlNextUpdate, "next-update", "24h", "next update, after this update")
+	crlCmd.Flags().StringVar(&config.crlNumber, "number", "", "CRL number, current unix time by default")
+	crlCmd.Flags().StringVar(&config.crlDistributionPoint, "distribution-point", "", "URI of issuing distribution point")
+	crlCmd.Flags().BoolVar(&config.crlOmitExpired, "omit-expired", false, "revoked certificates already expired omitted")
+
+	// Output.
+	crlCmd.Flags().Var(config.crlFormat, "format", "output format: "+CRL_PEM+"|"+CRL_DER)
+	crlCmd.Flags().StringVar(&config.outFile, "out", "", "output file, standard output by default")
+}
+
+/*
+CRL main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func crlMain(args []string) {
+
+	checkLogginglevel(args)
+
+	now := time.Now()
+
+	// Parse content of the list.
+	nextUpdate, err := parseDuration(config.crlNextUpdate)
+	if err != nil || nextUpdate <= 0 {
+		exitWithError(EXIT_FAILURE, fmt.Errorf("invalid next update %q", config.crlNextUpdate))
+	}
+
+	number := big.NewInt(now.Unix())
+	if len(config.crlNumber) > 0 {
+		var ok bool
+		if number, ok = new(big.Int).SetString(config.crlNumber, 10); !ok || number.Sign() < 0 {
+			exitWithError(EXIT_FAILURE, fmt.Errorf("invalid CRL number %q", config.crlNumber))
+		}
+	}
+
+	// Load the signer.
+	caCertificate, signer, err := loadCA(config.caCert, config.caKey, config.caKeyPasswordFile)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	// Open the database.
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+
+	// Get revocations, joined with certificates.
+	entries, err := getCrlEntries(reader, caCertificate, now)
+	if err != nil {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), err)
+	}
+
+	// Close the database.
+	if err = reader.Close(); err != nil {
+		logError.Fatalln(err)
+	}
+
+	template := &x509.RevocationList{
+		Number:                    number,
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(nextUpdate),
+		RevokedCertificateEntries: entries,
+	}
+
+	if len(config.crlDistributionPoint) > 0 {
+		extension, err := getIssuingDistributionPoint(config.crlDistributionPoint)
+		if err != nil {
+			exitWithError(EXIT_FAILURE, err)
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, extension)
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, template, caCertificate, signer)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	if config.crlFormat.Value == CRL_PEM {
+		crl = pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})
+	}
+
+	if err := writeOutput(config.outFile, crl); err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("CRL number %s listing %d revoked certificates written.", number, len(entries))
+	}
+
+	// Summary of skipped records.
+	reportRecordErrors()
+}
+
+/*
+getCrlEntries lists revocations of certificates issued by given CA. Missing bucket gives no entries, malformed record
+fails in strict mode.
+
+	'thisReader' Opened database.
+	'thisCaCertificate' CA certificate signing the list.
+	'thisNow' Moment expiry is checked against.
+*/
+func getCrlEntries(thisReader *stepdb.Reader, thisCaCertificate *x509.Certificate,
+	thisNow time.Time) ([]x509.RevocationListEntry, error) {
+
+	var entries []x509.RevocationListEntry
+
+	revocationIterator := thisReader.X509Revocations()
+	if err := revocationIterator.Err(); err != nil {
+		if errors.Is(err, stepdb.ErrBucketNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for revocationIterator.Next() {
+		revocation, err := revocationIterator.Record()
+		if err != nil {
+			handleRecordError(err)
+			if config.strict {
+				return nil, err
+			}
+			continue
+		}
+
+		serial, ok := new(big.Int).SetString(revocation.Serial, 10)
+		if !ok {
+			recordErr := &stepdb.RecordError{Bucket: stepdb.BUCKET_REVOKED_X509_CERTS, Key: revocation.Serial,
+				Err: errors.New("key is not a decimal serial number")}
+			handleRecordError(recordErr)
+			if config.strict {
+				return nil, recordErr
+			}
+			continue
+		}
+
+		// Expiry is taken from the certificate, if found.
+		expiresAt := revocation.ExpiresAt
+		record, err := thisReader.X509Certificate(revocation.Serial)
+		switch {
+		case err == nil:
+			if !bytes.Equal(record.Certificate.RawIssuer, thisCaCertificate.RawSubject) {
+				if loggingLevel >= 1 { // Show info.
+					logInfo.Printf("%s skipped, issued by %s", revocation.Serial, record.Certificate.Issuer)
+				}
+				continue
+			}
+			expiresAt = record.Certificate.NotAfter
+		case database.IsErrNotFound(err):
+			if loggingLevel >= 1 { // Show info.
+				logInfo.Printf("%s not found in %s", revocation.Serial, stepdb.BUCKET_X509_CERTS)
+			}
+		default:
+			recordErr := &stepdb.RecordError{Bucket: stepdb.BUCKET_X509_CERTS, Key: revocation.Serial, Err: err}
+			handleRecordError(recordErr)
+			if config.strict {
+				return nil, recordErr
+			}
+			continue
+		}
+
+		if config.crlOmitExpired && !expiresAt.IsZero() && thisNow.After(expiresAt) {
+			if loggingLevel >= 2 { // Show info.
+				logInfo.Printf("%s omitted, expired at %s", revocation.Serial, expiresAt.UTC().Format(time.RFC3339))
+			}
+			continue
+		}
+
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: revocation.RevokedAt,
+			ReasonCode:     revocation.ReasonCode, // Unspecified is omitted, as RFC 5280 recommends.
+		})
+	}
+
+	return entries, nil
+}
+
+/*
+getIssuingDistributionPoint builds critical issuing distribution point extension, RFC 5280 section 5.2.5.
+
+	'thisUri' URI the list is published at.
+*/
+func getIssuingDistributionPoint(thisUri string) (pkix.Extension, error) {
+
+	type distributionPointName struct {
+		FullName []asn1.RawValue `asn1:"optional,tag:0"`
+	}
+	type issuingDistributionPoint struct {
+		DistributionPoint distributionPointName `asn1:"optional,tag:0"`
+	}
+
+	value, err := asn1.Marshal(issuingDistributionPoint{
+		DistributionPoint: distributionPointName{
+			FullName: []asn1.RawValue{{Tag: 6, Class: asn1.ClassContextSpecific, Bytes: []byte(thisUri)}},
+		},
+	})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+
+	return pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 28}, Critical: true, Value: value}, nil
+}
+
+/*
+loadCA reads CA certificate and its private key, confirming they match.
+
+	'thisCertPath' Location of the certificate.
+	'thisKeyPath' Location of the private key.
+	'thisPasswordPath' Location of the password, empty if the key is not encrypted.
+*/
+func loadCA(thisCertPath string, thisKeyPath string, thisPasswordPath string) (*x509.Certificate, crypto.Signer, error) {
+
+	certificate, err := loadCertificate(thisCertPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signer, err := loadSigner(thisKeyPath, thisPasswordPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(certificate.PublicKey) {
+		return nil, nil, fmt.Errorf("%q does not match %q", thisKeyPath, thisCertPath)
+	}
+
+	return certificate, signer, nil
+}
+
+/*
+writeOutput writes given content into file, or to standard output if none given.
+
+	'thisPath' Location of the file, empty for standard output.
+	'thisContent' Content to be written.
+*/
+func writeOutput(thisPath string, thisContent []byte) error {
+
+	if len(thisPath) == 0 {
+		_, err := os.Stdout.Write(thisContent)
+		return err
+	}
+
+	return os.WriteFile(thisPath, thisContent, 0644)
+}
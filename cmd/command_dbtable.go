@@ -4,8 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/smallstep/nosql"
-	"github.com/smallstep/nosql/database"
 	"github.com/spf13/cobra"
 )
 
@@ -19,16 +17,17 @@ Export data table out of the badger database of step-ca.`,
 	Use: `dbTable <PATH> <TABLE> [flags]
 
 Arguments:
-  PATH    location of the source database
+  PATH    location of the source database, omitted when --ca-config is given
   TABLE   name of Badger table to export
 
 Note:
   For list of tables see: https://raw.githubusercontent.com/smallstep/certificates/master/db/db.go`,
 
 	Aliases: []string{"dbtable"},
-	Example: "  step-badger dbTable ./db ssh_host_principals",
+	Example: `  step-badger dbTable ./db ssh_host_principals
+  step-badger dbTable --ca-config /etc/step-ca/config/ca.json ssh_host_principals`,
 
-	Args: cobra.ExactArgs(2),
+	Args: databaseArgs(1),
 
 	Run: func(cmd *cobra.Command, args []string) {
 		dbTableMain(args)
@@ -50,30 +49,31 @@ dbTable main function.
 */
 func dbTableMain(args []string) {
 
-	var (
-		err error
-		db  database.DB
-	)
-
 	checkLogginglevel(args)
 
 	// Open the database.
-	db, err = nosql.New("badgerv2", args[0], database.WithValueDir(args[0]))
+	dbConfig, args, err := getDbConfig(args)
 	if err != nil {
-		logError.Fatalln(err)
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
 	}
 
 	// Get records from the bucket.
-	records, err := db.List([]byte(args[1]))
+	records, err := reader.List(args[0])
 	if err != nil {
-		logError.Fatalln(err)
+		reader.Close()
+		exitWithError(getBucketExitCode(err), err)
 	}
 	if records == nil {
-		logError.Fatalln("no records found")
+		reader.Close()
+		exitWithError(EXIT_BUCKET_MISSING, "no records found")
 	}
 
 	// Close the database.
-	if err = db.Close(); err != nil {
+	if err = reader.Close(); err != nil {
 		logError.Fatalln(err)
 	}
 
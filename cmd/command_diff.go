@@ -0,0 +1,465 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"github.com/smallstep/nosql/database"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the shell command.
+var diffCmd = &cobra.Command{
+	Long: `
+Compare two snapshots of the badger database of step-ca.
+
+Reports x509 and ssh certificates issued, revoked and newly expired between
+the old and the new snapshot, and entries added, removed or changed in buckets
+given by --buckets. Certificates are newly expired when their validity ends
+between moments of both snapshots, taken from modification time of PATHs
+unless given by --old-time and --new-time.`,
+
+	Short:                 "Compare two database snapshots.",
+	DisableFlagsInUseLine: true,
+	Use: `diff <OLD_PATH> <NEW_PATH> [flags]
+
+Arguments:
+  OLD_PATH   location of the older database snapshot
+  NEW_PATH   location of the newer database snapshot`,
+
+	Example: `  step-badger diff ./db-2024-03-14 ./db-2024-03-15
+  step-badger diff ./db-old ./db-new --buckets acme_accounts,admins --emit json`,
+
+	Args: cobra.ExactArgs(2),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		diffMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	// Hide help command.
+	diffCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	diffCmd.Flags().SortFlags = false
+
+	// Content of the comparison.
+	diffCmd.Flags().StringVar(&config.diffBuckets, "buckets", "", "buckets compared entry by entry, comma separated")
+	diffCmd.Flags().StringVar(&config.diffOldTime, "old-time", "", "moment of the old snapshot, modification time of OLD_PATH by default")
+	diffCmd.Flags().StringVar(&config.diffNewTime, "new-time", "", "moment of the new snapshot, modification time of NEW_PATH by default")
+
+	// Format choice flags.
+	diffCmd.Flags().Var(config.emitSummaryFormat, "emit", "emit format: "+FORMAT_TABLE+"|"+FORMAT_JSON+"|"+
+		FORMAT_MARKDOWN+"|"+FORMAT_PLAIN+"|"+FORMAT_CSV+"|"+FORMAT_TSV)
+	diffCmd.Flags().BoolVar(&config.noHeader, "no-header", false, "header row omitted, csv and tsv only")
+	diffCmd.Flags().StringVar(&config.delimiter, "delimiter", "", "delimiter of csv and tsv, comma and tab by default")
+	diffCmd.Flags().Var(config.timeFormat, "time", "time format: "+TIME_ISO+"|"+TIME_SHORT)
+}
+
+/*
+Diff main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func diffMain(args []string) {
+
+	checkLogginglevel(args)
+
+	if len(config.caConfig) > 0 {
+		exitWithError(EXIT_FAILURE, errors.New("--ca-config is not supported by diff, both snapshots are given by PATH"))
+	}
+
+	// Snapshots are kept intact, their modification time included, unless copied anyway.
+	config.readOnly = config.readOnly || !config.snapshot
+
+	now := time.Now()
+
+	var buckets []string
+	for _, bucket := range strings.Split(config.diffBuckets, ",") {
+		if bucket = strings.TrimSpace(bucket); len(bucket) > 0 {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	diff := tDiff{OldPath: args[0], NewPath: args[1]}
+
+	// Moments of both snapshots.
+	var err error
+	if diff.OldTime, err = getSnapshotTime(diff.OldPath, config.diffOldTime, now); err != nil {
+		exitWithError(EXIT_FAILURE, fmt.Errorf("old time: %w", err))
+	}
+	if diff.NewTime, err = getSnapshotTime(diff.NewPath, config.diffNewTime, now); err != nil {
+		exitWithError(EXIT_FAILURE, fmt.Errorf("new time: %w", err))
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("comparing %s as of %s with %s as of %s", diff.OldPath, diff.OldTime.UTC().Format(time.RFC3339),
+			diff.NewPath, diff.NewTime.UTC().Format(time.RFC3339))
+	}
+
+	oldSnapshot := loadDiffSnapshot(diff.OldPath, buckets)
+	newSnapshot := loadDiffSnapshot(diff.NewPath, buckets)
+
+	diff.X509 = getX509Changes(oldSnapshot.x509Certificates, newSnapshot.x509Certificates, diff.OldTime, diff.NewTime)
+	diff.Ssh = getSshChanges(oldSnapshot.sshCertificates, newSnapshot.sshCertificates, diff.OldTime, diff.NewTime)
+	diff.Buckets = []tBucketChange{}
+	for _, bucket := range buckets {
+		diff.Buckets = append(diff.Buckets, getBucketChanges(bucket, oldSnapshot.entries[bucket], newSnapshot.entries[bucket])...)
+	}
+
+	switch format := config.emitSummaryFormat.Value; format {
+	case FORMAT_JSON:
+		if err := writeJson(os.Stdout, diff); err != nil {
+			logError.Panic(err)
+		}
+	case FORMAT_MARKDOWN:
+		emitRowsMarkdown(getDiffRows(diff))
+	case FORMAT_PLAIN:
+		emitRowsPlain(getDiffRows(diff))
+	case FORMAT_CSV, FORMAT_TSV:
+		emitRowsCsv(getDiffRows(diff), format)
+	default:
+		emitRowsTable(getDiffRows(diff))
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("%d x509, %d ssh and %d bucket changes found", len(diff.X509), len(diff.Ssh), len(diff.Buckets))
+	}
+
+	// Summary of skipped records.
+	reportRecordErrors()
+}
+
+/*
+loadDiffSnapshot reads certificates and entries of given buckets from one snapshot. Missing or empty buckets are
+taken as empty, as certificates of a kind may appear only in the newer snapshot.
+
+	'thisPath' Location of the database.
+	'thisBuckets' Buckets compared entry by entry.
+*/
+func loadDiffSnapshot(thisPath string, thisBuckets []string) tDiffSnapshot {
+
+	reader, err := openReader(stepdb.Config{Type: config.dbType.Value, DataSource: thisPath})
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, fmt.Errorf("%s: %w", thisPath, err))
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			logError.Fatalln(err)
+		}
+	}()
+
+	isTolerated := func(thisErr error) bool {
+		return thisErr == nil || errors.Is(thisErr, errNoRecords) || errors.Is(thisErr, stepdb.ErrBucketNotFound)
+	}
+
+	snapshot := tDiffSnapshot{entries: map[string][]*database.Entry{}}
+
+	if snapshot.x509Certificates, err = loadX509Certificates(reader, handleRecordError); !isTolerated(err) {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), fmt.Errorf("%s: %w", thisPath, err))
+	}
+	if snapshot.sshCertificates, err = loadSshCertificates(reader, handleRecordError); !isTolerated(err) {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), fmt.Errorf("%s: %w", thisPath, err))
+	}
+	for _, bucket := range thisBuckets {
+		if snapshot.entries[bucket], err = reader.List(bucket); !isTolerated(err) {
+			reader.Close()
+			exitWithError(getBucketExitCode(err), fmt.Errorf("%s: %w", thisPath, err))
+		}
+	}
+
+	return snapshot
+}
+
+/*
+getSnapshotTime returns moment of snapshot, as given or as the latest modification time of its files.
+
+	'thisPath' Location of the database, directory of badger or file of bbolt.
+	'thisTime' Moment given by flag, empty if none.
+	'thisNow' Moment relative dates are counted from.
+*/
+func getSnapshotTime(thisPath string, thisTime string, thisNow time.Time) (time.Time, error) {
+
+	if len(thisTime) > 0 {
+		return parseDate(thisTime, thisNow)
+	}
+
+	info, err := os.Stat(thisPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	modified := info.ModTime()
+	if info.IsDir() {
+		files, err := os.ReadDir(thisPath)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, file := range files {
+			if fileInfo, err := os.Stat(filepath.Join(thisPath, file.Name())); err == nil && fileInfo.ModTime().After(modified) {
+				modified = fileInfo.ModTime()
+			}
+		}
+	}
+
+	return modified, nil
+}
+
+/*
+getX509Changes lists x509 certificates issued, revoked and newly expired, in order of the change.
+
+	'thisOld' Certificates of the old snapshot.
+	'thisNew' Certificates of the new snapshot.
+	'thisOldTime' Moment of the old snapshot.
+	'thisNewTime' Moment of the new snapshot, validity is evaluated at.
+*/
+func getX509Changes(thisOld []tX509CertificateProvisionerRevocation, thisNew []tX509CertificateProvisionerRevocation,
+	thisOldTime time.Time, thisNewTime time.Time) []tX509Change {
+
+	old := make(map[string]tX509CertificateProvisionerRevocation)
+	for _, x509Certificate := range thisOld {
+		old[x509Certificate.X509Certificate.SerialNumber.String()] = x509Certificate
+	}
+
+	changes := []tX509Change{}
+	for _, x509Certificate := range thisNew {
+		x509Certificate.Validity = getValidity(x509Certificate.X509Revocation, x509Certificate.X509Certificate.NotAfter, thisNewTime)
+		oldCertificate, found := old[x509Certificate.X509Certificate.SerialNumber.String()]
+
+		if !found {
+			changes = append(changes, tX509Change{CHANGE_ISSUED, x509Certificate})
+		}
+		if x509Certificate.X509Revocation.IsRevoked() && !oldCertificate.X509Revocation.IsRevoked() {
+			changes = append(changes, tX509Change{CHANGE_REVOKED, x509Certificate})
+		} else if !x509Certificate.X509Revocation.IsRevoked() &&
+			isNewlyExpired(x509Certificate.X509Certificate.NotAfter, thisOldTime, thisNewTime) {
+			changes = append(changes, tX509Change{CHANGE_EXPIRED, x509Certificate})
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a, b tX509Change) int {
+		return getX509ChangeTime(a).Compare(getX509ChangeTime(b))
+	})
+
+	return changes
+}
+
+/*
+getSshChanges lists ssh certificates issued, revoked and newly expired, in order of the change.
+
+	'thisOld' Certificates of the old snapshot.
+	'thisNew' Certificates of the new snapshot.
+	'thisOldTime' Moment of the old snapshot.
+	'thisNewTime' Moment of the new snapshot, validity is evaluated at.
+*/
+func getSshChanges(thisOld []tSshCertificateWithRevocation, thisNew []tSshCertificateWithRevocation,
+	thisOldTime time.Time, thisNewTime time.Time) []tSshChange {
+
+	old := make(map[uint64]tSshCertificateWithRevocation)
+	for _, sshCertificate := range thisOld {
+		old[sshCertificate.SshCertificate.Serial] = sshCertificate
+	}
+
+	changes := []tSshChange{}
+	for _, sshCertificate := range thisNew {
+		finish := getSshFinish(sshCertificate.SshCertificate)
+		sshCertificate.Validity = getValidity(sshCertificate.SshCertificateRevocation, finish, thisNewTime)
+		oldCertificate, found := old[sshCertificate.SshCertificate.Serial]
+
+		if !found {
+			changes = append(changes, tSshChange{CHANGE_ISSUED, sshCertificate})
+		}
+		if sshCertificate.SshCertificateRevocation.IsRevoked() && !oldCertificate.SshCertificateRevocation.IsRevoked() {
+			changes = append(changes, tSshChange{CHANGE_REVOKED, sshCertificate})
+		} else if !sshCertificate.SshCertificateRevocation.IsRevoked() && !finish.IsZero() &&
+			isNewlyExpired(finish, thisOldTime, thisNewTime) {
+			changes = append(changes, tSshChange{CHANGE_EXPIRED, sshCertificate})
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a, b tSshChange) int {
+		return getSshChangeTime(a).Compare(getSshChangeTime(b))
+	})
+
+	return changes
+}
+
+/*
+isNewlyExpired reports whether validity ended after the old snapshot, but not after the new one.
+
+	'thisFinish' End of validity.
+	'thisOldTime' Moment of the old snapshot.
+	'thisNewTime' Moment of the new snapshot.
+*/
+func isNewlyExpired(thisFinish time.Time, thisOldTime time.Time, thisNewTime time.Time) bool {
+	return thisFinish.After(thisOldTime) && !thisFinish.After(thisNewTime)
+}
+
+/*
+getX509ChangeTime returns moment of the change: start of validity, revocation or end of validity.
+
+	'thisChange' Change of certificate.
+*/
+func getX509ChangeTime(thisChange tX509Change) time.Time {
+	switch thisChange.Change {
+	case CHANGE_REVOKED:
+		return thisChange.X509Revocation.RevokedAt
+	case CHANGE_EXPIRED:
+		return thisChange.X509Certificate.NotAfter
+	default:
+		return thisChange.X509Certificate.NotBefore
+	}
+}
+
+/*
+getSshChangeTime returns moment of the change: start of validity, revocation or end of validity.
+
+	'thisChange' Change of certificate.
+*/
+func getSshChangeTime(thisChange tSshChange) time.Time {
+	switch thisChange.Change {
+	case CHANGE_REVOKED:
+		return thisChange.SshCertificateRevocation.RevokedAt
+	case CHANGE_EXPIRED:
+		return time.Unix(int64(thisChange.SshCertificate.ValidBefore), 0)
+	default:
+		return time.Unix(int64(thisChange.SshCertificate.ValidAfter), 0)
+	}
+}
+
+/*
+getBucketChanges lists entries added, removed or changed in a bucket, in order of keys.
+
+	'thisBucket' Name of the bucket.
+	'thisOld' Entries of the old snapshot.
+	'thisNew' Entries of the new snapshot.
+*/
+func getBucketChanges(thisBucket string, thisOld []*database.Entry, thisNew []*database.Entry) []tBucketChange {
+
+	old := make(map[string][]byte)
+	for _, entry := range thisOld {
+		old[string(entry.Key)] = entry.Value
+	}
+
+	var changes []tBucketChange
+	for _, entry := range thisNew {
+		oldValue, found := old[string(entry.Key)]
+		delete(old, string(entry.Key))
+
+		switch {
+		case !found:
+			changes = append(changes, tBucketChange{Change: CHANGE_ADDED, Bucket: thisBucket, Key: entry.Key, NewValue: entry.Value})
+		case !bytes.Equal(oldValue, entry.Value):
+			changes = append(changes, tBucketChange{Change: CHANGE_CHANGED, Bucket: thisBucket, Key: entry.Key,
+				OldValue: oldValue, NewValue: entry.Value})
+		}
+	}
+	for key, oldValue := range old {
+		changes = append(changes, tBucketChange{Change: CHANGE_REMOVED, Bucket: thisBucket, Key: []byte(key), OldValue: oldValue})
+	}
+
+	slices.SortFunc(changes, func(a, b tBucketChange) int { return bytes.Compare(a.Key, b.Key) })
+
+	return changes
+}
+
+/*
+getDiffRows lays changes out as rows of a table, x509 first, then ssh and buckets.
+
+	'thisDiff' Differences of both snapshots.
+*/
+func getDiffRows(thisDiff tDiff) tRows {
+
+	rows := tRows{
+		header: []string{"Kind", "Change", "Serial/Key", "Subject", "Time", "Details"},
+		align:  []int{ALIGN_LEFT, ALIGN_LEFT, ALIGN_LEFT, ALIGN_LEFT, ALIGN_LEFT, ALIGN_LEFT},
+	}
+
+	for _, change := range thisDiff.X509 {
+		rows.appendRow(getX509ChangeCells(change)...)
+	}
+
+	for _, change := range thisDiff.Ssh {
+		rows.appendRow(getSshChangeCells(change)...)
+	}
+
+	for _, change := range thisDiff.Buckets {
+		rows.appendRow(change.Bucket, change.Change, getBucketKeyStr(change.Key), "", "",
+			fmt.Sprintf("%d -> %d bytes", len(change.OldValue), len(change.NewValue)))
+	}
+
+	return rows
+}
+
+/*
+getX509ChangeCells describes change of x509 certificate by kind, change, serial, subject, time and details, the
+provisioner when issued or the reason when revoked.
+
+	'thisChange' Change of certificate.
+*/
+func getX509ChangeCells(thisChange tX509Change) []string {
+
+	var details string
+	switch thisChange.Change {
+	case CHANGE_ISSUED:
+		details = thisChange.X509Provisioner.Name
+	case CHANGE_REVOKED:
+		details = getRevocationReasonCodeStr(thisChange.X509Revocation)
+	}
+
+	return []string{CERTS_X509, thisChange.Change, thisChange.X509Certificate.SerialNumber.String(),
+		thisChange.X509Certificate.Subject.String(), getTimeStr(getX509ChangeTime(thisChange)), details}
+}
+
+/*
+getSshChangeCells describes change of ssh certificate by kind, change, serial, principals, time and details, the
+key id when issued or the reason when revoked.
+
+	'thisChange' Change of certificate.
+*/
+func getSshChangeCells(thisChange tSshChange) []string {
+
+	var details string
+	switch thisChange.Change {
+	case CHANGE_ISSUED:
+		details = thisChange.SshCertificate.KeyId
+	case CHANGE_REVOKED:
+		details = getRevocationReasonCodeStr(thisChange.SshCertificateRevocation)
+	}
+
+	return []string{CERTS_SSH, thisChange.Change, strconv.FormatUint(thisChange.SshCertificate.Serial, 10),
+		strings.Join(thisChange.SshCertificate.ValidPrincipals, ","), getTimeStr(getSshChangeTime(thisChange)), details}
+}
+
+/*
+getBucketKeyStr returns key of bucket entry as text when printable, hex encoded otherwise.
+
+	'thisKey' Raw key.
+*/
+func getBucketKeyStr(thisKey []byte) string {
+
+	if utf8.Valid(thisKey) && !slices.ContainsFunc([]rune(string(thisKey)), func(r rune) bool { return !unicode.IsPrint(r) }) {
+		return string(thisKey)
+	}
+
+	return hex.EncodeToString(thisKey)
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// krlCmd represents the shell command.
+var krlCmd = &cobra.Command{
+	Long: `
+Generate OpenSSH key revocation list out of the badger database of step-ca.
+
+Serial numbers of revoked ssh certificates, and optionally their key ids, are
+listed in one section per signing CA key, taken from certificates' signature key.
+The list is used by sshd with RevokedKeys option.`,
+
+	Short:                 "Generate OpenSSH KRL.",
+	DisableFlagsInUseLine: true,
+	Use: `krl <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger krl ./db --out revoked_keys
+  step-badger krl ./db --key-ids --omit-expired --comment "step-ca" --out revoked_keys`,
+
+	Args: databaseArgs(0),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		krlMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(krlCmd)
+
+	// Hide help command.
+	krlCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	krlCmd.Flags().SortFlags = false
+
+	// Content of the list.
+	krlCmd.Flags().BoolVar(&config.krlKeyIds, "key-ids", false, "key ids of revoked certificates listed too")
+	krlCmd.Flags().BoolVar(&config.krlOmitExpired, "omit-expired", false, "revoked certificates already expired omitted")
+	krlCmd.Flags().StringVar(&config.krlVersion, "version", "", "KRL version, current unix time by default")
+	krlCmd.Flags().StringVar(&config.krlComment, "comment", "", "comment of the list")
+
+	// Output.
+	krlCmd.Flags().StringVar(&config.outFile, "out", "", "output file, standard output by default")
+}
+
+/*
+KRL main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func krlMain(args []string) {
+
+	checkLogginglevel(args)
+
+	now := time.Now()
+
+	version := uint64(now.Unix())
+	if len(config.krlVersion) > 0 {
+		var err error
+		if version, err = strconv.ParseUint(config.krlVersion, 10, 64); err != nil {
+			exitWithError(EXIT_FAILURE, fmt.Errorf("invalid KRL version %q", config.krlVersion))
+		}
+	}
+
+	// Open the database.
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+
+	// Get certificates, joined with revocations.
+	sshCertificates, err := loadSshCertificates(reader, handleRecordError)
+	if err != nil && !errors.Is(err, errNoRecords) {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), err)
+	}
+
+	// Close the database.
+	if err = reader.Close(); err != nil {
+		logError.Fatalln(err)
+	}
+
+	krlCertificates := getKrlCertificates(sshCertificates, now)
+
+	krl := marshalKrl(krlCertificates, version, now, config.krlComment)
+
+	if err := writeOutput(config.outFile, krl); err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		for _, krlCertificate := range krlCertificates {
+			logInfo.Printf("CA %s: %d serial numbers, %d key ids", ssh.FingerprintSHA256(krlCertificate.caKey),
+				len(krlCertificate.serials), len(krlCertificate.keyIds))
+		}
+		logInfo.Printf("KRL version %d written.", version)
+	}
+
+	// Summary of skipped records.
+	reportRecordErrors()
+}
+
+/*
+getKrlCertificates groups revoked certificates by signing CA key, in order of first appearance.
+
+	'thisSshCertificatesWithRevocations' Slice of certs.
+	'thisNow' Moment expiry is checked against.
+*/
+func getKrlCertificates(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation, thisNow time.Time) []*tKrlCertificates {
+
+	var krlCertificates []*tKrlCertificates
+	byCaKey := make(map[string]*tKrlCertificates)
+
+	for _, sshCertificateWithRevocation := range thisSshCertificatesWithRevocations {
+		sshCertificate := sshCertificateWithRevocation.SshCertificate
+		if !sshCertificateWithRevocation.SshCertificateRevocation.IsRevoked() || sshCertificate.SignatureKey == nil {
+			continue
+		}
+
+		if config.krlOmitExpired && thisNow.After(time.Unix(int64(sshCertificate.ValidBefore), 0)) &&
+			sshCertificate.ValidBefore != ssh.CertTimeInfinity {
+			if loggingLevel >= 2 { // Show info.
+				logInfo.Printf("%d omitted, expired", sshCertificate.Serial)
+			}
+			continue
+		}
+
+		caKey := string(sshCertificate.SignatureKey.Marshal())
+		krlCertificate, ok := byCaKey[caKey]
+		if !ok {
+			krlCertificate = &tKrlCertificates{caKey: sshCertificate.SignatureKey,
+				serials: make(map[uint64]bool), keyIds: make(map[string]bool)}
+			byCaKey[caKey] = krlCertificate
+			krlCertificates = append(krlCertificates, krlCertificate)
+		}
+
+		// Serial zero cannot be revoked by serial, OpenSSH rejects it.
+		if sshCertificate.Serial != 0 {
+			krlCertificate.serials[sshCertificate.Serial] = true
+		}
+		if config.krlKeyIds && len(sshCertificate.KeyId) > 0 {
+			krlCertificate.keyIds[sshCertificate.KeyId] = true
+		}
+	}
+
+	return krlCertificates
+}
+
+/*
+marshalKrl encodes KRL in binary form, with one certificates section per CA key.
+
+	'thisKrlCertificates' Revoked certificates grouped by CA key.
+	'thisVersion' Version of the list, increasing with every generation.
+	'thisNow' Generation date.
+	'thisComment' Comment of the list.
+*/
+func marshalKrl(thisKrlCertificates []*tKrlCertificates, thisVersion uint64, thisNow time.Time,
+	thisComment string) []byte {
+
+	krl := ssh.Marshal(tKrlHeader{
+		Magic:         KRL_MAGIC,
+		FormatVersion: KRL_FORMAT_VERSION,
+		KrlVersion:    thisVersion,
+		GeneratedDate: uint64(thisNow.Unix()),
+		Comment:       thisComment,
+	})
+
+	for _, krlCertificate := range thisKrlCertificates {
+		section := ssh.Marshal(tKrlCertificatesHeader{CaKey: krlCertificate.caKey.Marshal()})
+
+		if len(krlCertificate.serials) > 0 {
+			serials := make([]uint64, 0, len(krlCertificate.serials))
+			for serial := range krlCertificate.serials {
+				serials = append(serials, serial)
+			}
+			slices.Sort(serials)
+
+			var data []byte
+			for _, serial := range serials {
+				data = binary.BigEndian.AppendUint64(data, serial)
+			}
+			section = append(section, ssh.Marshal(tKrlSection{Type: KRL_SECTION_CERT_SERIAL_LIST, Data: data})...)
+		}
+
+		if len(krlCertificate.keyIds) > 0 {
+			keyIds := make([]string, 0, len(krlCertificate.keyIds))
+			for keyId := range krlCertificate.keyIds {
+				keyIds = append(keyIds, keyId)
+			}
+			slices.Sort(keyIds)
+
+			var data []byte
+			for _, keyId := range keyIds {
+				data = append(data, ssh.Marshal(struct{ KeyId string }{keyId})...)
+			}
+			section = append(section, ssh.Marshal(tKrlSection{Type: KRL_SECTION_CERT_KEY_ID, Data: data})...)
+		}
+
+		krl = append(krl, ssh.Marshal(tKrlSection{Type: KRL_SECTION_CERTIFICATES, Data: section})...)
+	}
+
+	return krl
+}
@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ocsp"
+)
+
+// ocspCmd represents the shell command.
+var ocspCmd = &cobra.Command{
+	Long: `
+Serve RFC 6960 OCSP responses out of the badger database of step-ca.
+
+Status of a certificate is good, revoked with time and reason, or unknown for serial
+numbers not found in x509_certs. Responses are signed with delegated responder's
+certificate and key. With --out-dir, responses of all certificates issued by
+the CA are written into files for OCSP stapling instead.`,
+
+	Short:                 "Serve OCSP responses.",
+	DisableFlagsInUseLine: true,
+	Use: `ocsp <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger ocsp ./db --issuer-cert intermediate_ca.crt --responder-cert ocsp.crt --responder-key ocsp.key
+  step-badger ocsp ./db --issuer-cert intermediate_ca.crt --responder-cert ocsp.crt --responder-key ocsp.key --out-dir ./staple`,
+
+	Args: databaseArgs(0),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ocspMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(ocspCmd)
+
+	// Hide help command.
+	ocspCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	ocspCmd.Flags().SortFlags = false
+
+	// Signer.
+	ocspCmd.Flags().StringVar(&config.issuerCert, "issuer-cert", "", "CA certificate that issued the certificates, PEM or DER")
+	ocspCmd.Flags().StringVar(&config.caCert, "responder-cert", "", "responder certificate signing the responses, PEM or DER")
+	ocspCmd.Flags().StringVar(&config.caKey, "responder-key", "", "private key of the responder certificate, PEM or DER")
+	ocspCmd.Flags().StringVar(&config.caKeyPasswordFile, "responder-key-password-file", "", "file holding password of encrypted private key")
+	ocspCmd.MarkFlagRequired("issuer-cert")
+	ocspCmd.MarkFlagRequired("responder-cert")
+	ocspCmd.MarkFlagRequired("responder-key")
+
+	// Content of responses.
+	ocspCmd.Flags().StringVar(&config.ocspValidity, "validity", "24h", "next update, after this update")
+
+	// Mode.
+	ocspCmd.Flags().StringVar(&config.ocspListen, "listen", ":8080", "address to listen on")
+	ocspCmd.Flags().StringVar(&config.serveRefresh, "refresh", "1m", "interval of re-reading the database")
+	ocspCmd.Flags().StringVar(&config.outDir, "out-dir", "", "directory responses are written into, instead of serving")
+}
+
+/*
+OCSP main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func ocspMain(args []string) {
+
+	checkLogginglevel(args)
+
+	validity, err := parseDuration(config.ocspValidity)
+	if err != nil || validity <= 0 {
+		exitWithError(EXIT_FAILURE, fmt.Errorf("invalid validity %q", config.ocspValidity))
+	}
+
+	// Load the signer.
+	issuer, err := loadCertificate(config.issuerCert)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+	responderCertificate, signer, err := loadCA(config.caCert, config.caKey, config.caKeyPasswordFile)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+	if !responderCertificate.Equal(issuer) {
+		if err := responderCertificate.CheckSignatureFrom(issuer); err != nil {
+			exitWithError(EXIT_FAILURE, fmt.Errorf("responder certificate is not issued by the CA: %w", err))
+		}
+		if !slices.Contains(responderCertificate.ExtKeyUsage, x509.ExtKeyUsageOCSPSigning) {
+			logWarning.Println("responder certificate lacks OCSP signing extended key usage, clients may reject responses")
+		}
+	}
+
+	responder := &tOcspResponder{issuer: issuer, responder: responderCertificate, signer: signer, validity: validity}
+
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+
+	// Pre-generate responses for stapling.
+	if len(config.outDir) > 0 {
+		reader, err := openReader(dbConfig)
+		if err != nil {
+			exitWithError(EXIT_DB_OPEN, err)
+		}
+		x509Certificates, err := loadX509Certificates(reader, handleRecordError)
+		if err != nil {
+			reader.Close()
+			exitWithError(getBucketExitCode(err), err)
+		}
+		if err = reader.Close(); err != nil {
+			logError.Fatalln(err)
+		}
+
+		if err := responder.writeResponses(x509Certificates, config.outDir); err != nil {
+			exitWithError(EXIT_FAILURE, err)
+		}
+
+		// Summary of skipped records.
+		reportRecordErrors()
+		return
+	}
+
+	refresh, err := parseDuration(config.serveRefresh)
+	if err != nil || refresh <= 0 {
+		exitWithError(EXIT_FAILURE, fmt.Errorf("invalid refresh interval %q", config.serveRefresh))
+	}
+
+	// Running step-ca holds the lock, hence the copy is read unless told otherwise.
+	if !config.readOnly {
+		config.snapshot = true
+	}
+
+	// Initial refresh, failure is reported by /healthz.
+	responder.refresh(dbConfig)
+
+	server := &http.Server{Addr: config.ocspListen, Handler: responder, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Periodic refresh.
+	go func() {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				responder.refresh(dbConfig)
+			}
+		}
+	}()
+
+	// Shutdown on signal.
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logInfo.Printf("listening on %s, refreshing every %s", config.ocspListen, refresh)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		exitWithError(EXIT_FAILURE, err)
+	}
+}
+
+/*
+refresh re-reads the database and replaces cached certificates. Cache is kept intact on failure.
+
+	'thisDbConfig' Location of the database.
+*/
+func (thisResponder *tOcspResponder) refresh(thisDbConfig stepdb.Config) {
+
+	// Only x509 certificates are read, malformed ssh records do not fail the refresh.
+	x509Certificates, _, _, err := readServeCertificates(thisDbConfig, false)
+
+	thisResponder.mutex.Lock()
+	defer thisResponder.mutex.Unlock()
+
+	thisResponder.refreshErr = err
+	if err != nil {
+		logWarning.Printf("refresh failed: %v", err)
+		return
+	}
+
+	thisResponder.certificates = make(map[string]tX509CertificateProvisionerRevocation, len(x509Certificates))
+	for _, x509Certificate := range x509Certificates {
+		thisResponder.certificates[x509Certificate.X509Certificate.SerialNumber.String()] = x509Certificate
+	}
+	thisResponder.refreshedAt = time.Now()
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("refreshed: %d x509 certificates", len(x509Certificates))
+	}
+}
+
+/*
+ServeHTTP answers OCSP requests sent by GET, base64 encoded in the path, or by POST. Also serves /healthz.
+*/
+func (thisResponder *tOcspResponder) ServeHTTP(thisWriter http.ResponseWriter, thisRequest *http.Request) {
+
+	if thisRequest.URL.Path == "/healthz" {
+		thisResponder.handleHealthz(thisWriter)
+		return
+	}
+
+	var (
+		rawRequest []byte
+		err        error
+	)
+
+	switch thisRequest.Method {
+	case http.MethodGet:
+		var path string
+		if path, err = url.PathUnescape(strings.TrimPrefix(thisRequest.URL.EscapedPath(), "/")); err == nil {
+			rawRequest, err = base64.StdEncoding.DecodeString(path)
+		}
+	case http.MethodPost:
+		rawRequest, err = io.ReadAll(io.LimitReader(thisRequest.Body, 64*1024))
+	default:
+		http.Error(thisWriter, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := ocsp.MalformedRequestErrorResponse
+	var nextUpdate time.Time
+	if err == nil {
+		response, nextUpdate = thisResponder.respond(rawRequest)
+	}
+
+	thisWriter.Header().Set("Content-Type", "application/ocsp-response")
+	if !nextUpdate.IsZero() {
+		thisWriter.Header().Set("Expires", nextUpdate.UTC().Format(http.TimeFormat))
+		thisWriter.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d, public, no-transform, must-revalidate",
+			int(time.Until(nextUpdate).Seconds())))
+	}
+	thisWriter.Write(response)
+}
+
+/*
+respond builds signed response to given request, or error response. Next update is zero for error responses.
+
+	'thisRawRequest' DER encoded OCSP request.
+*/
+func (thisResponder *tOcspResponder) respond(thisRawRequest []byte) ([]byte, time.Time) {
+
+	request, err := ocsp.ParseRequest(thisRawRequest)
+	if err != nil {
+		return ocsp.MalformedRequestErrorResponse, time.Time{}
+	}
+
+	if !isIssuedBy(request, thisResponder.issuer) {
+		return ocsp.UnauthorizedErrorResponse, time.Time{}
+	}
+
+	thisResponder.mutex.RLock()
+	if thisResponder.refreshedAt.IsZero() {
+		thisResponder.mutex.RUnlock()
+		return ocsp.TryLaterErrorResponse, time.Time{}
+	}
+	x509Certificate, ok := thisResponder.certificates[request.SerialNumber.String()]
+	thisResponder.mutex.RUnlock()
+
+	var found *tX509CertificateProvisionerRevocation
+	if ok {
+		found = &x509Certificate
+	}
+
+	response, template, err := thisResponder.createResponse(request.SerialNumber, found, request.HashAlgorithm)
+	if err != nil {
+		logWarning.Printf("signing response failed: %v", err)
+		return ocsp.InternalErrorErrorResponse, time.Time{}
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%s: %s", request.SerialNumber, getOcspStatusStr()[template.Status])
+	}
+
+	return response, template.NextUpdate
+}
+
+/*
+createResponse signs response about given certificate, status computed from its validity.
+
+	'thisSerial' Serial number asked for.
+	'thisCertificate' Certificate of the serial number, nil if not found or issued by another CA.
+	'thisHash' Hash algorithm of the issuer's name and key.
+*/
+func (thisResponder *tOcspResponder) createResponse(thisSerial *big.Int,
+	thisCertificate *tX509CertificateProvisionerRevocation, thisHash crypto.Hash) ([]byte, ocsp.Response, error) {
+
+	now := time.Now()
+	template := ocsp.Response{
+		Status:       ocsp.Unknown,
+		SerialNumber: thisSerial,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(thisResponder.validity),
+		IssuerHash:   thisHash,
+	}
+
+	if thisCertificate != nil && bytes.Equal(thisCertificate.X509Certificate.RawIssuer, thisResponder.issuer.RawSubject) {
+		switch getValidity(thisCertificate.X509Revocation, thisCertificate.X509Certificate.NotAfter, now) {
+		case REVOKED_STR:
+			template.Status = ocsp.Revoked
+			template.RevokedAt = thisCertificate.X509Revocation.RevokedAt
+			template.RevocationReason = thisCertificate.X509Revocation.ReasonCode
+		default: // Expired certificates are not revoked either.
+			template.Status = ocsp.Good
+		}
+	}
+
+	// Delegated responder includes its certificate.
+	if !thisResponder.responder.Equal(thisResponder.issuer) {
+		template.Certificate = thisResponder.responder
+	}
+
+	response, err := ocsp.CreateResponse(thisResponder.issuer, thisResponder.responder, template, thisResponder.signer)
+
+	return response, template, err
+}
+
+/*
+writeResponses writes DER encoded response of every certificate issued by the CA, named after decimal serial number.
+
+	'thisX509CertsWithRevocations' Slice of certs.
+	'thisDir' Directory the files are written into.
+*/
+func (thisResponder *tOcspResponder) writeResponses(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation,
+	thisDir string) error {
+
+	if err := os.MkdirAll(thisDir, 0755); err != nil {
+		return err
+	}
+
+	var count int
+	for i := range thisX509CertsWithRevocations {
+		x509CertWithRevocation := &thisX509CertsWithRevocations[i]
+		if !bytes.Equal(x509CertWithRevocation.X509Certificate.RawIssuer, thisResponder.issuer.RawSubject) {
+			continue
+		}
+
+		serial := x509CertWithRevocation.X509Certificate.SerialNumber
+		response, _, err := thisResponder.createResponse(serial, x509CertWithRevocation, crypto.SHA1)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(thisDir, serial.String()+".der"), response, 0644); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("%d responses written into %s", count, thisDir)
+	}
+
+	return nil
+}
+
+/*
+handleHealthz reports whether last refresh succeeded.
+*/
+func (thisResponder *tOcspResponder) handleHealthz(thisWriter http.ResponseWriter) {
+
+	thisResponder.mutex.RLock()
+	defer thisResponder.mutex.RUnlock()
+
+	switch {
+	case thisResponder.refreshErr != nil:
+		http.Error(thisWriter, thisResponder.refreshErr.Error(), http.StatusServiceUnavailable)
+	case thisResponder.refreshedAt.IsZero():
+		http.Error(thisWriter, "not refreshed yet", http.StatusServiceUnavailable)
+	default:
+		fmt.Fprintf(thisWriter, "ok, refreshed at %s\n", thisResponder.refreshedAt.UTC().Format(time.RFC3339))
+	}
+}
+
+/*
+isIssuedBy reports whether request asks about certificate of given CA, comparing hashes of its name and key.
+
+	'thisRequest' Parsed OCSP request.
+	'thisIssuer' CA certificate.
+*/
+func isIssuedBy(thisRequest *ocsp.Request, thisIssuer *x509.Certificate) bool {
+
+	if !thisRequest.HashAlgorithm.Available() {
+		return false
+	}
+
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(thisIssuer.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return false
+	}
+
+	hash := thisRequest.HashAlgorithm.New()
+	hash.Write(thisIssuer.RawSubject)
+	nameHash := hash.Sum(nil)
+
+	hash.Reset()
+	hash.Write(publicKeyInfo.PublicKey.RightAlign())
+	keyHash := hash.Sum(nil)
+
+	return bytes.Equal(nameHash, thisRequest.IssuerNameHash) && bytes.Equal(keyHash, thisRequest.IssuerKeyHash)
+}
+
+/*
+getOcspStatusStr maps OCSP status to its name.
+*/
+func getOcspStatusStr() map[int]string {
+	return map[int]string{
+		ocsp.Good:    "good",
+		ocsp.Revoked: "revoked",
+		ocsp.Unknown: "unknown",
+	}
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"github.com/spf13/cobra"
+)
+
+// provisionersCmd represents the shell command.
+var provisionersCmd = &cobra.Command{
+	Long: `
+List provisioners out of the badger database of step-ca.
+
+Provisioners are read from the provisioners bucket of remote administration,
+from authority.provisioners of ca.json given by --ca-config, and from
+x509_certs_data for those known only from certificates they issued. Each is
+shown with counts of x509 certificates it issued, of those currently valid,
+expired and revoked, and with dates of its first and last issuance.`,
+
+	Short:                 "List provisioners.",
+	DisableFlagsInUseLine: true,
+	Use: `provisioners <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger provisioners ./db
+  step-badger provisioners --ca-config /etc/step-ca/config/ca.json --read-only --emit markdown`,
+
+	Args: databaseArgs(0),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		provisionersMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(provisionersCmd)
+
+	// Hide help command.
+	provisionersCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	provisionersCmd.Flags().SortFlags = false
+
+	// Format choice flags.
+	provisionersCmd.Flags().Var(config.emitSummaryFormat, "emit", "emit format: "+FORMAT_TABLE+"|"+FORMAT_JSON+"|"+
+		FORMAT_MARKDOWN+"|"+FORMAT_PLAIN+"|"+FORMAT_CSV+"|"+FORMAT_TSV)
+	provisionersCmd.Flags().BoolVar(&config.noHeader, "no-header", false, "header row omitted, csv and tsv only")
+	provisionersCmd.Flags().StringVar(&config.delimiter, "delimiter", "", "delimiter of csv and tsv, comma and tab by default")
+	provisionersCmd.Flags().Var(config.timeFormat, "time", "time format: "+TIME_ISO+"|"+TIME_SHORT)
+}
+
+/*
+Provisioners main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func provisionersMain(args []string) {
+
+	checkLogginglevel(args)
+
+	var provisionerSummaries []tProvisionerSummary
+
+	// Get provisioners of ca.json.
+	if len(config.caConfig) > 0 {
+		caProvisioners, err := stepdb.LoadCAProvisioners(config.caConfig)
+		if err != nil {
+			exitWithError(EXIT_FAILURE, err)
+		}
+		for _, caProvisioner := range caProvisioners {
+			provisionerSummaries = addProvisionerSource(provisionerSummaries, caProvisioner, PROVISIONER_FROM_CA_CONFIG)
+		}
+	}
+
+	// Open the database.
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+
+	// Get provisioners of remote administration, not present unless enabled.
+	provisionerIterator := reader.Provisioners()
+	if err := provisionerIterator.Err(); err != nil && !errors.Is(err, stepdb.ErrBucketNotFound) {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), err)
+	}
+	for provisionerIterator.Next() {
+		adminProvisioner, err := provisionerIterator.Record()
+		if err != nil {
+			handleRecordError(err)
+			if config.strict {
+				reader.Close()
+				exitWithError(EXIT_PARSE_ERROR, err)
+			}
+			continue
+		}
+
+		provisionerSummaries = addProvisionerSource(provisionerSummaries, stepdb.Provisioner{
+			ID:   adminProvisioner.ID,
+			Name: adminProvisioner.Name,
+			Type: stepdb.ProvisionerTypeStr()[adminProvisioner.Type],
+		}, PROVISIONER_FROM_DB)
+		if adminProvisioner.IsDeleted() {
+			provisionerSummaries[len(provisionerSummaries)-1].Deleted = true
+		}
+	}
+
+	// Get certificates, joined with provisioners.
+	x509Certificates, err := loadX509Certificates(reader, handleRecordError)
+	if err != nil && !errors.Is(err, errNoRecords) && !errors.Is(err, stepdb.ErrBucketNotFound) {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), err)
+	}
+
+	// Close the database.
+	if err = reader.Close(); err != nil {
+		logError.Fatalln(err)
+	}
+
+	provisionerSummaries = countProvisionerCertificates(provisionerSummaries, x509Certificates)
+
+	switch format := config.emitSummaryFormat.Value; format {
+	case FORMAT_JSON:
+		if err := writeJson(os.Stdout, provisionerSummaries); err != nil {
+			logError.Panic(err)
+		}
+	case FORMAT_MARKDOWN:
+		emitRowsMarkdown(getProvisionerRows(provisionerSummaries))
+	case FORMAT_PLAIN:
+		emitRowsPlain(getProvisionerRows(provisionerSummaries))
+	case FORMAT_CSV, FORMAT_TSV:
+		emitRowsCsv(getProvisionerRows(provisionerSummaries), format)
+	default:
+		emitRowsTable(getProvisionerRows(provisionerSummaries))
+	}
+
+	// Summary of skipped records.
+	reportRecordErrors()
+}
+
+/*
+addProvisionerSource records that provisioner is known from given source, adding it if not yet present.
+Provisioners are matched by ID. The returned slice is to be used further.
+
+	'thisProvisionerSummaries' Provisioners known so far.
+	'thisProvisioner' Provisioner found.
+	'thisSource' Where it was found, one of PROVISIONER_FROM_* values.
+*/
+func addProvisionerSource(thisProvisionerSummaries []tProvisionerSummary, thisProvisioner stepdb.Provisioner,
+	thisSource string) []tProvisionerSummary {
+
+	index := slices.IndexFunc(thisProvisionerSummaries, func(p tProvisionerSummary) bool {
+		return p.ID == thisProvisioner.ID
+	})
+	if index < 0 {
+		thisProvisionerSummaries = append(thisProvisionerSummaries, tProvisionerSummary{
+			ID:   thisProvisioner.ID,
+			Name: thisProvisioner.Name,
+			Type: thisProvisioner.Type,
+		})
+		index = len(thisProvisionerSummaries) - 1
+	}
+
+	summary := &thisProvisionerSummaries[index]
+	if !slices.Contains(summary.Sources, thisSource) {
+		summary.Sources = append(summary.Sources, thisSource)
+	}
+
+	return thisProvisionerSummaries
+}
+
+/*
+countProvisionerCertificates counts certificates issued by each provisioner, adding those known only from
+certificates. Provisioners are returned sorted by name, then by ID.
+
+	'thisProvisionerSummaries' Provisioners known so far.
+	'thisX509Certificates' Certificates, joined with provisioners.
+*/
+func countProvisionerCertificates(thisProvisionerSummaries []tProvisionerSummary,
+	thisX509Certificates []tX509CertificateProvisionerRevocation) []tProvisionerSummary {
+
+	for _, x509Certificate := range thisX509Certificates {
+		thisProvisionerSummaries = addProvisionerSource(thisProvisionerSummaries, x509Certificate.X509Provisioner,
+			PROVISIONER_FROM_CERTIFICATES)
+
+		summary := &thisProvisionerSummaries[slices.IndexFunc(thisProvisionerSummaries, func(p tProvisionerSummary) bool {
+			return p.ID == x509Certificate.X509Provisioner.ID
+		})]
+
+		summary.Issued++
+		switch x509Certificate.Validity {
+		case VALID_STR:
+			summary.Valid++
+		case EXPIRED_STR:
+			summary.Expired++
+		case REVOKED_STR:
+			summary.Revoked++
+		}
+
+		notBefore := x509Certificate.X509Certificate.NotBefore
+		if summary.FirstIssued.IsZero() || notBefore.Before(summary.FirstIssued) {
+			summary.FirstIssued = notBefore
+		}
+		if notBefore.After(summary.LastIssued) {
+			summary.LastIssued = notBefore
+		}
+	}
+
+	slices.SortStableFunc(thisProvisionerSummaries, func(a, b tProvisionerSummary) int {
+		if result := strings.Compare(a.Name, b.Name); result != 0 {
+			return result
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return thisProvisionerSummaries
+}
+
+/*
+getProvisionerRows lays provisioners out as rows of a table.
+
+	'thisProvisionerSummaries' Provisioners, with counts of their certificates.
+*/
+func getProvisionerRows(thisProvisionerSummaries []tProvisionerSummary) tRows {
+
+	rows := tRows{
+		header: []string{"ID", "Name", "Type", "Source", "Issued", "Valid", "Expired", "Revoked", "First issued", "Last issued"},
+		align: []int{ALIGN_LEFT, ALIGN_LEFT, ALIGN_LEFT, ALIGN_LEFT, ALIGN_RIGHT, ALIGN_RIGHT, ALIGN_RIGHT, ALIGN_RIGHT,
+			ALIGN_LEFT, ALIGN_LEFT},
+	}
+
+	for _, summary := range thisProvisionerSummaries {
+		sources := strings.Join(summary.Sources, ",")
+		if summary.Deleted {
+			sources += " (deleted)"
+		}
+
+		rows.appendRow(summary.ID, summary.Name, summary.Type, sources,
+			strconv.Itoa(summary.Issued), strconv.Itoa(summary.Valid), strconv.Itoa(summary.Expired),
+			strconv.Itoa(summary.Revoked), getTimeStr(summary.FirstIssued), getTimeStr(summary.LastIssued))
+	}
+
+	return rows
+}
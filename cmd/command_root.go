@@ -35,13 +35,13 @@ var (
 		}(".", commitHash)
 )
 
-var config tConfig // Holds configuration.
+var config = newConfig() // Holds configuration.
 
 // rootCmd represents the base command when called without any subcommands.
 var rootCmd = &cobra.Command{
 	Use:               "step-badger",
 	Short:             "Export step-ca data from badger.",
-	Long:              `Export certificate or table data from the badger database of step-ca. Requires off-line database directory.`,
+	Long:              `Export certificate or table data from the badger database of step-ca. Database held by running step-ca is read with --snapshot from a copy, or with --read-only when its lock is free or shared.`,
 	Version:           semReleaseVersion,
 	DisableAutoGenTag: true, // Do not add footer to autogenerated help.
 
@@ -64,13 +64,12 @@ var (
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(EXIT_FAILURE)
 	}
 }
 
 func init() {
 	initLoggers()
-	initChoices()
 
 	// Hide help command.
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
@@ -81,6 +80,17 @@ func init() {
 	// Adding global ie. persistent logging level flag.
 	rootCmd.PersistentFlags().IntVar(&loggingLevel, "logging", 0,
 		fmt.Sprintf("logging level [0...%d] (default 0)", MAX_LOGGING_LEVEL))
+
+	// Adding global ie. persistent database type flag.
+	rootCmd.PersistentFlags().Var(config.dbType, "db-type", "database type: "+DB_AUTO+"|"+DB_BADGERV1+"|"+DB_BADGERV2+"|"+DB_BBOLT)
+	rootCmd.PersistentFlags().StringVar(&config.caConfig, "ca-config", "", "step-ca's ca.json to read database location from, replaces PATH")
+	rootCmd.PersistentFlags().BoolVar(&config.readOnly, "read-only", false, "database opened in read-only mode")
+	rootCmd.PersistentFlags().BoolVar(&config.snapshot, "snapshot", false, "database copied into temporary directory and read from the copy")
+	rootCmd.MarkFlagsMutuallyExclusive("read-only", "snapshot")
+
+	// Adding global ie. persistent strict mode flag.
+	rootCmd.PersistentFlags().BoolVar(&config.strict, "strict", false,
+		fmt.Sprintf("malformed record fails with exit code %d, instead of being skipped", EXIT_PARSE_ERROR))
 }
 
 /*
@@ -95,8 +105,8 @@ For convenience it also emits some log if loggingLevel >= 1.
 	'thisArgs' Values emitted to log.
 */
 func checkLogginglevel(thisArgs []string) {
-	if loggingLevel > MAX_LOGGING_LEVEL {
-		logError.Fatalln(fmt.Errorf("%s", rootCmd.Flag("logging").Usage))
+	if err := getLogginglevelErr(); err != nil {
+		logError.Fatalln(err)
 	}
 
 	if loggingLevel >= 1 { // Show info.
@@ -104,3 +114,13 @@ func checkLogginglevel(thisArgs []string) {
 		logInfo.Printf("loggingLevel: %d. config: %#v\n", loggingLevel, config)
 	}
 }
+
+/*
+getLogginglevelErr returns error if logging level exceeds maximum level, nil otherwise.
+*/
+func getLogginglevelErr() error {
+	if loggingLevel > MAX_LOGGING_LEVEL {
+		return fmt.Errorf("%s", rootCmd.Flag("logging").Usage)
+	}
+	return nil
+}
@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the shell command.
+var serveCmd = &cobra.Command{
+	Long: `
+Serve certificates' data out of the badger database of step-ca over HTTP.
+
+The database is periodically re-read from a snapshot copy, or in read-only mode
+when --read-only is given, and parsed certificates are cached in between.
+
+Endpoints:
+  /api/x509           x509 certificates, as x509Certs --emit json
+  /api/x509/{serial}  single x509 certificate, decimal or hex serial
+  /api/ssh            ssh certificates, as sshCerts --emit json
+  /metrics            metrics in the Prometheus text exposition format
+  /healthz            200 if last refresh succeeded, 503 otherwise
+
+Query parameters of /api/x509 and /api/ssh are named after the flags of x509Certs
+and sshCerts respectively, e.g. ?revoked=true&match-san=*.example.com&sort=start`,
+
+	Short:                 "Serve certificates over HTTP.",
+	DisableFlagsInUseLine: true,
+	Use: `serve <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger serve ./db
+  step-badger serve ./db --listen 127.0.0.1:9876 --refresh 5m
+  step-badger serve --ca-config /etc/step-ca/config/ca.json --read-only`,
+
+	Args: databaseArgs(0),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		serveMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	// Hide help command.
+	serveCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	serveCmd.Flags().SortFlags = false
+
+	serveCmd.Flags().StringVar(&config.serveListen, "listen", ":9876", "address to listen on")
+	serveCmd.Flags().StringVar(&config.serveRefresh, "refresh", "1m", "interval of re-reading the database")
+}
+
+/*
+Serve main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func serveMain(args []string) {
+
+	checkLogginglevel(args)
+
+	refresh, err := parseDuration(config.serveRefresh)
+	if err != nil || refresh <= 0 {
+		exitWithError(EXIT_FAILURE, fmt.Errorf("invalid refresh interval %q", config.serveRefresh))
+	}
+
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+
+	// Running step-ca holds the lock, hence the copy is read unless told otherwise.
+	if !config.readOnly {
+		config.snapshot = true
+	}
+
+	cache := &tServeCache{}
+
+	// Initial refresh, failure is reported by /healthz.
+	refreshServeCache(cache, dbConfig)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/x509", cache.handleX509)
+	mux.HandleFunc("GET /api/x509/{serial}", cache.handleX509Serial)
+	mux.HandleFunc("GET /api/ssh", cache.handleSsh)
+	mux.HandleFunc("GET /metrics", cache.handleMetrics)
+	mux.HandleFunc("GET /healthz", cache.handleHealthz)
+
+	server := &http.Server{Addr: config.serveListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Periodic refresh.
+	go func() {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refreshServeCache(cache, dbConfig)
+			}
+		}
+	}()
+
+	// Shutdown on signal.
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logInfo.Printf("listening on %s, refreshing every %s", config.serveListen, refresh)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		exitWithError(EXIT_FAILURE, err)
+	}
+}
+
+/*
+refreshServeCache re-reads the database and replaces cached certificates. Cache is kept intact on failure.
+
+	'thisCache' Cache to be refreshed.
+	'thisDbConfig' Location of the database.
+*/
+func refreshServeCache(thisCache *tServeCache, thisDbConfig stepdb.Config) {
+
+	x509Certificates, sshCertificates, malformed, err := readServeCertificates(thisDbConfig, true)
+
+	thisCache.mutex.Lock()
+	defer thisCache.mutex.Unlock()
+
+	thisCache.refreshErr = err
+	if err != nil {
+		thisCache.refreshFailures++
+		logWarning.Printf("refresh failed: %v", err)
+		return
+	}
+
+	thisCache.x509 = x509Certificates
+	thisCache.ssh = sshCertificates
+	thisCache.malformed = malformed
+	thisCache.refreshedAt = time.Now()
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("refreshed: %d x509, %d ssh certificates, %d malformed records skipped",
+			len(x509Certificates), len(sshCertificates), malformed)
+	}
+}
+
+/*
+readServeCertificates reads certificates. Missing or empty buckets give no certificates.
+
+	'thisDbConfig' Location of the database.
+	'thisWithSsh' Whether ssh certificates are read too, their malformed records counting as well.
+*/
+func readServeCertificates(thisDbConfig stepdb.Config, thisWithSsh bool) ([]tX509CertificateProvisionerRevocation,
+	[]tSshCertificateWithRevocation, int, error) {
+
+	reader, err := openReader(thisDbConfig)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer reader.Close()
+
+	var malformedErrs []error
+	collectRecordError := func(thisErr error) {
+		malformedErrs = append(malformedErrs, thisErr)
+	}
+
+	isEmpty := func(thisErr error) bool {
+		return errors.Is(thisErr, errNoRecords) || errors.Is(thisErr, stepdb.ErrBucketNotFound)
+	}
+
+	x509Certificates, err := loadX509Certificates(reader, collectRecordError)
+	if err != nil && !isEmpty(err) {
+		return nil, nil, 0, err
+	}
+
+	var sshCertificates []tSshCertificateWithRevocation
+	if thisWithSsh {
+		sshCertificates, err = loadSshCertificates(reader, collectRecordError)
+		if err != nil && !isEmpty(err) {
+			return nil, nil, 0, err
+		}
+	}
+
+	return x509Certificates, sshCertificates, len(malformedErrs), nil
+}
+
+/*
+handleX509 serves selected x509 certificates, as x509Certs --emit json does.
+*/
+func (thisCache *tServeCache) handleX509(thisWriter http.ResponseWriter, thisRequest *http.Request) {
+
+	var filterArgs tFilterArgs
+	filter, sortOrder, err := parseServeQuery(thisRequest, []tServeParam{
+		{"match-subject", &filterArgs.subject},
+		{"match-san", &filterArgs.san},
+		{"match-provisioner", &filterArgs.provisioner},
+		{"match-provisioner-type", &filterArgs.provisionerType},
+		{"match-serial", &filterArgs.serial},
+		{"match-issuer", &filterArgs.issuer},
+	}, &filterArgs)
+	if err != nil {
+		http.Error(thisWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sortKeys, err := getX509SortKeys(sortOrder)
+	if err != nil {
+		http.Error(thisWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	thisCache.mutex.RLock()
+	x509CertificatesProvisionersRevocations := []tX509CertificateProvisionerRevocation{}
+	for _, x509CertificateProvisionerRevocation := range thisCache.x509 {
+		if filter.matchX509(x509CertificateProvisionerRevocation) {
+			x509CertificatesProvisionersRevocations = append(x509CertificatesProvisionersRevocations,
+				x509CertificateProvisionerRevocation)
+		}
+	}
+	thisCache.mutex.RUnlock()
+
+	sortX509Certificates(x509CertificatesProvisionersRevocations, sortKeys)
+
+	writeServeJson(thisWriter, x509CertificatesProvisionersRevocations)
+}
+
+/*
+handleX509Serial serves single x509 certificate of given serial number, regardless of its validity.
+*/
+func (thisCache *tServeCache) handleX509Serial(thisWriter http.ResponseWriter, thisRequest *http.Request) {
+
+	serial, err := parseSerial(thisRequest.PathValue("serial"))
+	if err != nil {
+		http.Error(thisWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	thisCache.mutex.RLock()
+	defer thisCache.mutex.RUnlock()
+
+	for _, x509CertificateProvisionerRevocation := range thisCache.x509 {
+		if x509CertificateProvisionerRevocation.X509Certificate.SerialNumber.Cmp(serial) == 0 {
+			writeServeJson(thisWriter, x509CertificateProvisionerRevocation)
+			return
+		}
+	}
+
+	http.NotFound(thisWriter, thisRequest)
+}
+
+/*
+handleSsh serves selected ssh certificates, as sshCerts --emit json does.
+*/
+func (thisCache *tServeCache) handleSsh(thisWriter http.ResponseWriter, thisRequest *http.Request) {
+
+	var filterArgs tFilterArgs
+	filter, sortOrder, err := parseServeQuery(thisRequest, []tServeParam{
+		{"match-principal", &filterArgs.subject},
+		{"match-principal-glob", &filterArgs.san},
+		{"match-serial", &filterArgs.serial},
+		{"match-keyid", &filterArgs.keyId},
+	}, &filterArgs)
+	if err != nil {
+		http.Error(thisWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sortKeys, err := getSshSortKeys(sortOrder)
+	if err != nil {
+		http.Error(thisWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	thisCache.mutex.RLock()
+	sshCertificatesWithRevocations := []tSshCertificateWithRevocation{}
+	for _, sshCertificateWithRevocation := range thisCache.ssh {
+		if filter.matchSsh(sshCertificateWithRevocation) {
+			sshCertificatesWithRevocations = append(sshCertificatesWithRevocations, sshCertificateWithRevocation)
+		}
+	}
+	thisCache.mutex.RUnlock()
+
+	sortSshCertificates(sshCertificatesWithRevocations, sortKeys)
+
+	writeServeJson(thisWriter, sshCertificatesWithRevocations)
+}
+
+/*
+handleMetrics serves metrics of all cached certificates, along with metrics of refreshing.
+*/
+func (thisCache *tServeCache) handleMetrics(thisWriter http.ResponseWriter, _ *http.Request) {
+
+	thisCache.mutex.RLock()
+	defer thisCache.mutex.RUnlock()
+
+	var success float64
+	if thisCache.refreshErr == nil && !thisCache.refreshedAt.IsZero() {
+		success = 1
+	}
+
+	families := append(getX509MetricFamilies(thisCache.x509), getSshMetricFamilies(thisCache.ssh)...)
+	families = append(families,
+		tMetricFamily{name: "last_refresh_success", kind: METRIC_GAUGE, help: "Whether last refresh of the database succeeded.",
+			samples: []tMetricSample{{value: success}}},
+		tMetricFamily{name: "last_refresh_seconds", kind: METRIC_GAUGE, help: "Last successful refresh, in seconds since epoch.",
+			samples: []tMetricSample{{value: float64(thisCache.refreshedAt.Unix())}}},
+		tMetricFamily{name: "refresh_failures_total", kind: METRIC_COUNTER, help: "Number of failed refreshes.",
+			samples: []tMetricSample{{value: float64(thisCache.refreshFailures)}}},
+		tMetricFamily{name: "malformed_records", kind: METRIC_GAUGE, help: "Malformed records skipped during last refresh.",
+			samples: []tMetricSample{{value: float64(thisCache.malformed)}}},
+	)
+
+	thisWriter.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writePrometheus(thisWriter, families)
+}
+
+/*
+handleHealthz reports whether last refresh succeeded.
+*/
+func (thisCache *tServeCache) handleHealthz(thisWriter http.ResponseWriter, _ *http.Request) {
+
+	thisCache.mutex.RLock()
+	defer thisCache.mutex.RUnlock()
+
+	switch {
+	case thisCache.refreshErr != nil:
+		http.Error(thisWriter, thisCache.refreshErr.Error(), http.StatusServiceUnavailable)
+	case thisCache.refreshedAt.IsZero():
+		http.Error(thisWriter, "not refreshed yet", http.StatusServiceUnavailable)
+	default:
+		fmt.Fprintf(thisWriter, "ok, refreshed at %s\n", thisCache.refreshedAt.UTC().Format(time.RFC3339))
+	}
+}
+
+/*
+parseServeQuery turns query parameters into record selection criteria and sort order.
+Validity parameters default as the flags of x509Certs and sshCerts do.
+
+	'thisRequest' Request of the API.
+	'thisParams' Parameters specific to the endpoint.
+	'thisFilterArgs' Criteria the parameters are bound to.
+*/
+func parseServeQuery(thisRequest *http.Request, thisParams []tServeParam, thisFilterArgs *tFilterArgs) (tFilter, string, error) {
+
+	query := thisRequest.URL.Query()
+
+	for _, param := range append(thisParams, []tServeParam{
+		{"issued-after", &thisFilterArgs.issuedAfter},
+		{"issued-before", &thisFilterArgs.issuedBefore},
+		{"expires-after", &thisFilterArgs.expiresAfter},
+		{"expires-before", &thisFilterArgs.expiresBefore},
+		{"revoked-after", &thisFilterArgs.revokedAfter},
+		{"revoked-before", &thisFilterArgs.revokedBefore},
+	}...) {
+		*param.target = query.Get(param.name)
+	}
+
+	filter, err := thisFilterArgs.parse(time.Now())
+	if err != nil {
+		return tFilter{}, "", err
+	}
+
+	getBool := func(thisName string, thisDefault bool) (bool, error) {
+		if !query.Has(thisName) {
+			return thisDefault, nil
+		}
+		value, err := strconv.ParseBool(query.Get(thisName))
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", thisName, err)
+		}
+		return value, nil
+	}
+
+	valid, err := getBool("valid", true)
+	if err != nil {
+		return tFilter{}, "", err
+	}
+	revoked, err := getBool("revoked", false)
+	if err != nil {
+		return tFilter{}, "", err
+	}
+	expired, err := getBool("expired", false)
+	if err != nil {
+		return tFilter{}, "", err
+	}
+	filter.validities = getSelectedValidities(valid, revoked, expired)
+
+	sortOrder := SORT_DEFAULT
+	if query.Has("sort") {
+		sortOrder = query.Get("sort")
+	}
+
+	return filter, sortOrder, nil
+}
+
+/*
+writeServeJson writes response as indented json.
+
+	'thisWriter' Response writer.
+	'thisValue' Value to be marshalled.
+*/
+func writeServeJson(thisWriter http.ResponseWriter, thisValue any) {
+
+	thisWriter.Header().Set("Content-Type", "application/json")
+
+	if err := writeJson(thisWriter, thisValue); err != nil {
+		logWarning.Printf("writing response failed: %v", err)
+	}
+}
@@ -1,16 +1,9 @@
 package cmd
 
 import (
-	"sort"
-	"strconv"
-	"strings"
 	"time"
 
-	"github.com/pkg/errors"
-	"github.com/smallstep/nosql"
-	"github.com/smallstep/nosql/database"
 	"github.com/spf13/cobra"
-	"golang.org/x/crypto/ssh"
 )
 
 // sshCertsCmd represents the shell command.
@@ -23,14 +16,20 @@ Export ssh certificates' data out of the badger database of step-ca.`,
 	Use: `sshCerts <PATH> [flags]
 
 Arguments:
-  PATH   location of the source database`,
+  PATH   location of the source database, omitted when --ca-config is given`,
 
 	Aliases: []string{"sshcerts"},
-	Example: "  step-badger sshCerts ./db",
+	Example: `  step-badger sshCerts ./db
+  step-badger sshCerts ./db --match-principal '^root$' --issued-after -7d
+  step-badger sshCerts --ca-config /etc/step-ca/config/ca.json`,
 
-	Args: cobra.ExactArgs(1),
+	Args: columnsArgs(databaseArgs(0)),
 
 	Run: func(cmd *cobra.Command, args []string) {
+		if config.listColumns {
+			emitSshColumnList()
+			return
+		}
 		exportSshMain(args)
 	},
 }
@@ -51,13 +50,31 @@ func init() {
 	sshCertsCmd.Flags().BoolVarP(&config.showValid, "valid", "v", true, "valid certificates shown")
 	sshCertsCmd.Flags().BoolVarP(&config.showRevoked, "revoked", "r", false, "revoked certificates shown")
 	sshCertsCmd.Flags().BoolVarP(&config.showExpired, "expired", "e", false, "expired certificates shown")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.subject, "match-principal", "", "principal regex")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.san, "match-principal-glob", "", "principal glob")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.serial, "match-serial", "", "serial number, decimal or hex")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.keyId, "match-keyid", "", "key id glob")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.issuedAfter, "issued-after", "", "start after date or relative duration, e.g. 2026-01-01 or -30d")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.issuedBefore, "issued-before", "", "start before date or relative duration")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.expiresAfter, "expires-after", "", "finish after date or relative duration")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.expiresBefore, "expires-before", "", "finish before date or relative duration, e.g. 30d")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.revokedAfter, "revoked-after", "", "revoked after date or relative duration")
+	sshCertsCmd.Flags().StringVar(&config.filterArgs.revokedBefore, "revoked-before", "", "revoked before date or relative duration")
 
 	// Format choice
-	sshCertsCmd.Flags().Var(config.emitSshFormat, "emit", "emit format: "+FORMAT_TABLE+"|"+FORMAT_JSON+"|"+FORMAT_MARKDOWN+"|"+FORMAT_PLAIN)
+	sshCertsCmd.Flags().Var(config.emitSshFormat, "emit", "emit format: "+FORMAT_TABLE+"|"+FORMAT_JSON+"|"+FORMAT_MARKDOWN+"|"+FORMAT_PLAIN+
+		"|"+FORMAT_PROMETHEUS+"|"+FORMAT_CSV+"|"+FORMAT_TSV+"|"+FORMAT_TEMPLATE+"|"+FORMAT_HTML+"|"+FORMAT_OPENSSH)
+	sshCertsCmd.Flags().BoolVar(&config.noHeader, "no-header", false, "header row omitted, csv and tsv only")
+	sshCertsCmd.Flags().StringVar(&config.delimiter, "delimiter", "", "delimiter of csv and tsv, comma and tab by default")
+	sshCertsCmd.Flags().StringVar(&config.templateFile, "template", "", "text/template file, template only")
+	sshCertsCmd.Flags().StringVar(&config.outDir, "output-dir", "", "directory certificate files and manifest are written into, openssh only")
+	sshCertsCmd.Flags().Var(config.fileName, "file-name", "file named after: "+FILE_NAME_SERIAL+"|"+FILE_NAME_SUBJECT)
 	sshCertsCmd.Flags().Var(config.timeFormat, "time", "time format: "+TIME_ISO+"|"+TIME_SHORT)
-	sshCertsCmd.Flags().Var(config.sortOrder, "sort", "sort order: "+SORT_START+"|"+SORT_FINISH)
+	sshCertsCmd.Flags().StringVar(&config.sortOrder, "sort", SORT_DEFAULT, "sort keys, column ids, - prefix for descending, e.g. type,-finish,principals")
 
 	// Columns selection criteria.
+	sshCertsCmd.Flags().StringVar(&config.columns, "columns", "", "columns shown, in order, e.g. serial,subject,finish; or +id,-id tweaking the defaults")
+	sshCertsCmd.Flags().BoolVar(&config.listColumns, "list-columns", false, "ids of columns listed")
 	sshCertsCmd.Flags().BoolVar(&config.showHostType, "type", true, "host type column shown")
 	sshCertsCmd.Flags().BoolVar(&config.showSerial, "serial", true, "serial column shown")
 	sshCertsCmd.Flags().BoolVar(&config.showKeyId, "keyid", false, "key id column shown")
@@ -72,91 +89,54 @@ func exportSshMain(args []string) {
 
 	checkLogginglevel(args)
 
-	var (
-		err error
-		db  database.DB
+	var sshCertificatesWithRevocations []tSshCertificateWithRevocation
 
-		sshCertificateWithRevocation   tSshCertificateWithRevocation
-		sshCertificatesWithRevocations []tSshCertificateWithRevocation
-	)
+	// Parse record selection criteria.
+	filter, err := config.filterArgs.parse(time.Now())
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+	filter.validities = getSelectedValidities(config.showValid, config.showRevoked, config.showExpired)
 
-	// Open the database.
-	db, err = nosql.New("badgerv2", args[0], database.WithValueDir(args[0]))
+	// Validate columns selection, before the database is read.
+	getShownSshColumns()
+	sortKeys, err := getSshSortKeys(config.sortOrder)
 	if err != nil {
-		logError.Fatalln(err)
+		exitWithError(EXIT_FAILURE, err)
 	}
 
-	// Get records from the ssh_certs bucket.
-	records, err := db.List([]byte("ssh_certs"))
+	// Open the database.
+	dbConfig, _, err := getDbConfig(args)
 	if err != nil {
-		logError.Fatalln(err)
+		exitWithError(EXIT_DB_OPEN, err)
 	}
-	if records == nil {
-		logError.Fatalln("no records found")
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
 	}
 
-	for _, record := range records {
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("Bucket: %s", record.Bucket)
-			logInfo.Printf("Key: %s", record.Key)
-			logInfo.Printf("Value: %q", record.Value)
-		}
-
-		// Get certificate.
-		sshCertificate := parseValueToSshCertificate(record.Value)
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("Serial: %s", strconv.FormatUint(sshCertificate.Serial, 10))
-			logInfo.Printf("Subject: %s", strings.Join(sshCertificate.ValidPrincipals, ","))
-		}
-
-		// Get revocation.
-		sshCertificateRevocation := getSshRevocation(db, sshCertificate)
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("RevocationProvisionerID: %s", sshCertificateRevocation.ProvisionerID)
-		}
-
-		// Populate the child.
-		sshCertificateWithRevocation = tSshCertificateWithRevocation{
-			SshCertificate:           sshCertificate,
-			SshCertificateRevocation: sshCertificateRevocation,
-		}
-
-		// Populate child validity info of the certificate.
-		if len(sshCertificateRevocation.ProvisionerID) > 0 && time.Now().After(sshCertificateRevocation.RevokedAt) {
-			sshCertificateWithRevocation.Validity = REVOKED_STR
-		} else {
-			if time.Now().After(time.Unix(int64(sshCertificate.ValidBefore), 0)) {
-				sshCertificateWithRevocation.Validity = EXPIRED_STR
-			} else {
-				sshCertificateWithRevocation.Validity = VALID_STR
-			}
-		}
-
-		// Append child into collection, if record selection criteria are met.
-		if (config.showExpired && sshCertificateWithRevocation.Validity == EXPIRED_STR) ||
-			(config.showRevoked && sshCertificateWithRevocation.Validity == REVOKED_STR) ||
-			(config.showValid && sshCertificateWithRevocation.Validity == VALID_STR) {
-			sshCertificatesWithRevocations = append(sshCertificatesWithRevocations, sshCertificateWithRevocation)
-		}
+	// Get certificates joined with revocation.
+	sshCertificates, err := loadSshCertificates(reader, handleRecordError)
+	if err != nil {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), err)
 	}
 
 	// Close the database.
-	if err = db.Close(); err != nil {
+	if err = reader.Close(); err != nil {
 		logError.Fatalln(err)
 	}
 
-	// Sort.
-	switch thisSort := config.sortOrder.Value; thisSort {
-	case SORT_FINISH:
-		sort.SliceStable(sshCertificatesWithRevocations, func(i, j int) bool {
-			return sshCertificatesWithRevocations[i].SshCertificate.ValidBefore < sshCertificatesWithRevocations[j].SshCertificate.ValidBefore
-		})
-	case SORT_START:
-		sort.SliceStable(sshCertificatesWithRevocations, func(i, j int) bool {
-			return sshCertificatesWithRevocations[i].SshCertificate.ValidAfter < sshCertificatesWithRevocations[j].SshCertificate.ValidAfter
-		})
+	// Append child into collection, if record selection criteria are met.
+	for _, sshCertificateWithRevocation := range sshCertificates {
+		if filter.matchSsh(sshCertificateWithRevocation) {
+			sshCertificatesWithRevocations = append(sshCertificatesWithRevocations, sshCertificateWithRevocation)
+		}
 	}
 
+	// Sort.
+	sortSshCertificates(sshCertificatesWithRevocations, sortKeys)
+
 	// Output.
 	switch format := config.emitSshFormat.Value; format {
 	case FORMAT_JSON:
@@ -167,45 +147,18 @@ func exportSshMain(args []string) {
 		emitSshCertsMarkdown(sshCertificatesWithRevocations)
 	case FORMAT_PLAIN:
 		emitSshCertsPlain(sshCertificatesWithRevocations)
-	}
-}
-
-func getSshRevocation(thisDB database.DB, thisSshCertificate ssh.Certificate) tCertificateRevocation {
-
-	revocationValue, err := thisDB.Get([]byte("revoked_ssh_certs"), []byte(strconv.FormatUint(thisSshCertificate.Serial, 10)))
-
-	switch {
-	case errors.Is(err, database.ErrNotFound):
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("key for revocation not found")
-		}
-	case err != nil:
-		logInfo.Panic(err)
-	}
-
-	if loggingLevel >= 2 { // Show info.
-		logInfo.Printf("revocationValue: %s", revocationValue)
-	}
-
-	return parseValueToCertificateRevocation(revocationValue)
-}
-
-func parseValueToSshCertificate(thisValue []byte) ssh.Certificate {
-
-	var (
-		sshCertificate *ssh.Certificate
-	)
-
-	// Parse the SSH certificate.
-	pubKey, err := ssh.ParsePublicKey(thisValue)
-	if err != nil {
-		logError.Panicf("Error parsing SSH certificate: %v", err)
-	}
-
-	sshCertificate, ok := pubKey.(*ssh.Certificate)
-	if !ok {
-		logError.Panicf("Key is not an SSH certificate")
+	case FORMAT_CSV, FORMAT_TSV:
+		emitSshCertsCsv(sshCertificatesWithRevocations, format)
+	case FORMAT_TEMPLATE:
+		emitTemplate(sshCertificatesWithRevocations)
+	case FORMAT_HTML:
+		emitSshCertsHtml(sshCertificatesWithRevocations)
+	case FORMAT_PROMETHEUS:
+		emitSshCertsPrometheus(sshCertificatesWithRevocations)
+	case FORMAT_OPENSSH:
+		emitSshCertsOpenSsh(sshCertificatesWithRevocations)
 	}
 
-	return *sshCertificate
+	// Summary of skipped records.
+	reportRecordErrors()
 }
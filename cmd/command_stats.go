@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the shell command.
+var statsCmd = &cobra.Command{
+	Long: `
+Report statistics of certificates out of the badger database of step-ca.
+
+Certificates of x509_certs and ssh_certs are counted by validity, provisioner,
+key algorithm and ssh certificate type, and by period of issuance. Distribution
+of their lifetime is given by minimum, median, 95th percentile and maximum.
+Valid certificates are counted by upcoming windows of expiry.`,
+
+	Short:                 "Report statistics of certificates.",
+	DisableFlagsInUseLine: true,
+	Use: `stats <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger stats ./db
+  step-badger stats ./db --certs x509 --period week --expiring 1d,7d,30d --emit json`,
+
+	Args: databaseArgs(0),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		statsMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	// Hide help command.
+	statsCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	statsCmd.Flags().SortFlags = false
+
+	// Content of the statistics.
+	statsCmd.Flags().Var(config.checkCerts, "certs", "certificates counted: "+CERTS_ALL+"|"+CERTS_X509+"|"+CERTS_SSH)
+	statsCmd.Flags().Var(config.statsPeriod, "period", "period of issuance counts: "+PERIOD_DAY+"|"+PERIOD_WEEK+"|"+PERIOD_MONTH)
+	statsCmd.Flags().StringVar(&config.statsExpiring, "expiring", "7d,30d,90d", "windows of upcoming expiry, comma separated")
+
+	// Format choice flags.
+	statsCmd.Flags().Var(config.emitStatsFormat, "emit", "emit format: "+FORMAT_TABLE+"|"+FORMAT_JSON+"|"+FORMAT_MARKDOWN)
+}
+
+/*
+Stats main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func statsMain(args []string) {
+
+	checkLogginglevel(args)
+
+	now := time.Now()
+
+	// Parse windows of expiry.
+	var windowKeys []string
+	var windows []time.Duration
+	for _, item := range strings.Split(config.statsExpiring, ",") {
+		if item = strings.TrimSpace(item); len(item) == 0 {
+			continue
+		}
+		window, err := parseDuration(item)
+		if err != nil {
+			exitWithError(EXIT_FAILURE, fmt.Errorf("expiring: %w", err))
+		}
+		windowKeys = append(windowKeys, item)
+		windows = append(windows, window)
+	}
+
+	// Open the database.
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+
+	stats := tStats{Generated: now.UTC(), Period: config.statsPeriod.Value}
+
+	// Observe x509 certificates.
+	if config.checkCerts.Value != CERTS_SSH {
+		x509Certificates, err := loadX509Certificates(reader, handleRecordError)
+		if err != nil && !isBucketTolerated(err) {
+			reader.Close()
+			exitWithError(getBucketExitCode(err), err)
+		}
+
+		var observations []tStatsObservation
+		for _, x509Certificate := range x509Certificates {
+			observations = append(observations, tStatsObservation{
+				validity:     x509Certificate.Validity,
+				provisioner:  x509Certificate.X509Provisioner.Name,
+				keyAlgorithm: getPublicKeyStr(x509Certificate.X509Certificate.PublicKey),
+				start:        x509Certificate.X509Certificate.NotBefore,
+				finish:       x509Certificate.X509Certificate.NotAfter,
+			})
+		}
+		stats.X509 = getCertStats(observations, now, windowKeys, windows)
+	}
+
+	// Observe ssh certificates.
+	if config.checkCerts.Value != CERTS_X509 {
+		sshCertificates, err := loadSshCertificates(reader, handleRecordError)
+		if err != nil && !isBucketTolerated(err) {
+			reader.Close()
+			exitWithError(getBucketExitCode(err), err)
+		}
+
+		// Validity counts those valid forever as valid, unless revoked.
+		var observations []tStatsObservation
+		for _, sshCertificate := range sshCertificates {
+			finish := getSshFinish(sshCertificate.SshCertificate)
+			observations = append(observations, tStatsObservation{
+				validity:     sshCertificate.Validity,
+				keyAlgorithm: getSshPublicKeyStr(sshCertificate.SshCertificate.Key),
+				certType:     getCertType()[int(sshCertificate.SshCertificate.CertType)],
+				start:        time.Unix(int64(sshCertificate.SshCertificate.ValidAfter), 0),
+				finish:       finish,
+				forever:      finish.IsZero(),
+			})
+		}
+		stats.Ssh = getCertStats(observations, now, windowKeys, windows)
+	}
+
+	// Close the database.
+	if err = reader.Close(); err != nil {
+		logError.Fatalln(err)
+	}
+
+	switch config.emitStatsFormat.Value {
+	case FORMAT_JSON:
+		if err := writeJson(os.Stdout, stats); err != nil {
+			logError.Panic(err)
+		}
+	case FORMAT_MARKDOWN:
+		emitStatsMarkdown(getStatsSections(stats))
+	default:
+		emitStatsTable(getStatsSections(stats))
+	}
+
+	// Summary of skipped records.
+	reportRecordErrors()
+}
+
+/*
+getCertStats computes statistics of certificates of one kind.
+
+	'thisObservations' Properties of each certificate.
+	'thisNow' Moment validity and expiry are evaluated at.
+	'thisWindowKeys' Windows of upcoming expiry, as given.
+	'thisWindows' Windows of upcoming expiry, parsed.
+*/
+func getCertStats(thisObservations []tStatsObservation, thisNow time.Time, thisWindowKeys []string,
+	thisWindows []time.Duration) *tCertStats {
+
+	byValidity := map[string]int{VALID_STR: 0, EXPIRED_STR: 0, REVOKED_STR: 0}
+	byProvisioner := map[string]int{}
+	byKeyAlgorithm := map[string]int{}
+	byType := map[string]int{}
+	issuance := map[string]int{}
+	expiring := make([]int, len(thisWindows))
+	var lifetimes []int64
+	var forever int
+
+	for _, observation := range thisObservations {
+		byValidity[observation.validity]++
+		if len(observation.provisioner) > 0 {
+			byProvisioner[observation.provisioner]++
+		}
+		byKeyAlgorithm[observation.keyAlgorithm]++
+		if len(observation.certType) > 0 {
+			byType[observation.certType]++
+		}
+		issuance[getPeriodKey(observation.start)]++
+
+		if observation.forever {
+			forever++
+			continue
+		}
+		lifetimes = append(lifetimes, int64(observation.finish.Sub(observation.start).Seconds()))
+
+		if observation.validity == VALID_STR {
+			for index, window := range thisWindows {
+				if observation.finish.Sub(thisNow) <= window {
+					expiring[index]++
+				}
+			}
+		}
+	}
+
+	certStats := tCertStats{
+		Total:          len(thisObservations),
+		ByProvisioner:  getStatsCounts(byProvisioner),
+		ByKeyAlgorithm: getStatsCounts(byKeyAlgorithm),
+		ByType:         getStatsCounts(byType),
+		Lifetime:       getLifetimeStats(lifetimes),
+		Expiring:       []tStatsCount{},
+	}
+	certStats.Lifetime.Forever = forever
+
+	// Validities in fixed order.
+	for _, validity := range []string{VALID_STR, EXPIRED_STR, REVOKED_STR} {
+		certStats.ByValidity = append(certStats.ByValidity, tStatsCount{Key: validity, Count: byValidity[validity]})
+	}
+
+	// Periods in chronological order, keys sort so.
+	certStats.Issuance = getStatsCounts(issuance)
+	slices.SortFunc(certStats.Issuance, func(a, b tStatsCount) int { return strings.Compare(a.Key, b.Key) })
+
+	// Windows in order given.
+	for index, windowKey := range thisWindowKeys {
+		certStats.Expiring = append(certStats.Expiring, tStatsCount{Key: windowKey, Count: expiring[index]})
+	}
+
+	return &certStats
+}
+
+/*
+getStatsCounts turns counts by key into slice, most frequent first, then by key.
+
+	'thisCounts' Counts by key.
+*/
+func getStatsCounts(thisCounts map[string]int) []tStatsCount {
+
+	statsCounts := []tStatsCount{}
+	for key, count := range thisCounts {
+		statsCounts = append(statsCounts, tStatsCount{Key: key, Count: count})
+	}
+
+	slices.SortFunc(statsCounts, func(a, b tStatsCount) int {
+		if result := cmp.Compare(b.Count, a.Count); result != 0 {
+			return result
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return statsCounts
+}
+
+/*
+getPeriodKey names period of given time as --period says, e.g. 2024-03-15, 2024-W11 or 2024-03.
+
+	'thisTime' Time within the period.
+*/
+func getPeriodKey(thisTime time.Time) string {
+
+	switch thisTime = thisTime.UTC(); config.statsPeriod.Value {
+	case PERIOD_DAY:
+		return thisTime.Format(time.DateOnly)
+	case PERIOD_WEEK:
+		year, week := thisTime.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	default:
+		return thisTime.Format("2006-01")
+	}
+}
+
+/*
+getLifetimeStats computes distribution of lifetimes, percentiles by nearest rank.
+
+	'thisLifetimes' Lifetimes in seconds, in any order.
+*/
+func getLifetimeStats(thisLifetimes []int64) tLifetimeStats {
+
+	if len(thisLifetimes) == 0 {
+		return tLifetimeStats{}
+	}
+
+	slices.Sort(thisLifetimes)
+	percentile := func(thisPercent float64) int64 {
+		rank := int(math.Ceil(thisPercent / 100 * float64(len(thisLifetimes))))
+		return thisLifetimes[max(rank-1, 0)]
+	}
+
+	return tLifetimeStats{
+		Min:    thisLifetimes[0],
+		Median: percentile(50),
+		P95:    percentile(95),
+		Max:    thisLifetimes[len(thisLifetimes)-1],
+	}
+}
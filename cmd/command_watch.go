@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// watchCmd represents the shell command.
+var watchCmd = &cobra.Command{
+	Long: `
+Watch the badger database of step-ca, streaming certificate events.
+
+The database is re-read at given interval and compared with the previous read,
+emitting one event per x509 or ssh certificate issued, revoked or expired in
+between. The first read sets the baseline and emits nothing. Events are printed
+as human-readable lines, or as JSON Lines shaped as the json output of
+certificates with Kind, Change and Time added.
+
+Running step-ca holds the lock, hence the database is read from a snapshot,
+unless --read-only is given.`,
+
+	Short:                 "Stream certificate events.",
+	DisableFlagsInUseLine: true,
+	Use: `watch <PATH> [flags]
+
+Arguments:
+  PATH   location of the source database, omitted when --ca-config is given`,
+
+	Example: `  step-badger watch ./db
+  step-badger watch --ca-config /etc/step-ca/config/ca.json --interval 30s --emit jsonl`,
+
+	Args: databaseArgs(0),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		watchMain(args)
+	},
+}
+
+/*
+Cobra initiation.
+*/
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	// Hide help command.
+	watchCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	//Do not sort flags.
+	watchCmd.Flags().SortFlags = false
+
+	// Re-reading.
+	watchCmd.Flags().StringVar(&config.watchInterval, "interval", "1m", "interval of re-reading the database")
+
+	// Format choice flags.
+	watchCmd.Flags().Var(config.emitWatchFormat, "emit", "emit format: "+FORMAT_PLAIN+"|"+FORMAT_JSONL)
+	watchCmd.Flags().Var(config.timeFormat, "time", "time format: "+TIME_ISO+"|"+TIME_SHORT+", plain only")
+}
+
+/*
+Watch main function.
+
+	'args' Given command line arguments, that contain the command to be run by shell.
+*/
+func watchMain(args []string) {
+
+	checkLogginglevel(args)
+
+	interval, err := parseDuration(config.watchInterval)
+	if err != nil || interval <= 0 {
+		exitWithError(EXIT_FAILURE, fmt.Errorf("invalid interval %q", config.watchInterval))
+	}
+
+	dbConfig, _, err := getDbConfig(args)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
+	}
+
+	// Running step-ca holds the lock, hence the copy is read unless told otherwise.
+	if !config.readOnly {
+		config.snapshot = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("watching every %s", interval)
+	}
+
+	// Initial read sets the baseline.
+	watcher := &tWatcher{dbConfig: dbConfig}
+	watcher.poll()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			watcher.poll()
+		}
+	}
+}
+
+/*
+poll re-reads the database and emits events since the last successful read. Certificates read are kept as the
+baseline of the next poll. Failure is reported and retried at the next poll.
+*/
+func (thisWatcher *tWatcher) poll() {
+
+	now := time.Now()
+
+	x509Certificates, sshCertificates, malformed, err := readServeCertificates(thisWatcher.dbConfig, true)
+	if err != nil {
+		logWarning.Printf("read failed: %v", err)
+		return
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("read: %d x509, %d ssh certificates, %d malformed records skipped",
+			len(x509Certificates), len(sshCertificates), malformed)
+	}
+
+	if !thisWatcher.readAt.IsZero() {
+		for _, change := range getX509Changes(thisWatcher.x509, x509Certificates, thisWatcher.readAt, now) {
+			emitWatchEvent(tX509Event{Kind: CERTS_X509, Time: getX509ChangeTime(change), tX509Change: change},
+				getX509ChangeCells(change))
+		}
+		for _, change := range getSshChanges(thisWatcher.ssh, sshCertificates, thisWatcher.readAt, now) {
+			emitWatchEvent(tSshEvent{Kind: CERTS_SSH, Time: getSshChangeTime(change), tSshChange: change},
+				getSshChangeCells(change))
+		}
+	}
+
+	thisWatcher.x509 = x509Certificates
+	thisWatcher.ssh = sshCertificates
+	thisWatcher.readAt = now
+}
+
+/*
+emitWatchEvent prints event as JSON Line, or as human-readable line: time, kind, change, serial, subject and details.
+
+	'thisEvent' Event, marshalled as JSON Line.
+	'thisCells' Event described by kind, change, serial, subject, time and details.
+*/
+func emitWatchEvent(thisEvent any, thisCells []string) {
+
+	if config.emitWatchFormat.Value == FORMAT_JSONL {
+		jsonLine, err := json.Marshal(thisEvent)
+		if err != nil {
+			logError.Panic(err)
+		}
+		fmt.Println(string(jsonLine))
+		return
+	}
+
+	fmt.Println(strings.TrimSpace(fmt.Sprintf("%s %s %s %s %q %s",
+		thisCells[4], thisCells[0], thisCells[1], thisCells[2], thisCells[3], thisCells[5])))
+}
@@ -1,17 +1,8 @@
 package cmd
 
 import (
-	"crypto/x509"
-	"encoding/json"
-	"encoding/pem"
-	"fmt"
-	"sort"
-	"strings"
 	"time"
 
-	"github.com/pkg/errors"
-	"github.com/smallstep/nosql"
-	"github.com/smallstep/nosql/database"
 	"github.com/spf13/cobra"
 )
 
@@ -25,15 +16,22 @@ Export x509 certificates' data out of the badger database of step-ca.`,
 	Use: `x509Certs <PATH> [flags]
 
 Arguments:
-  PATH   location of the source database`,
+  PATH   location of the source database, omitted when --ca-config is given`,
 
 	Aliases: []string{"x509certs"},
 	Example: `  step-badger x509certs ./db
-  step-badger x509Certs ./db --revoked --valid=false --emit=openssl`,
+  step-badger x509Certs ./db --revoked --valid=false --emit=openssl
+  step-badger x509Certs ./db --revoked --expired --emit=prometheus > /var/lib/node_exporter/step-badger.prom
+  step-badger x509Certs ./db --match-san '*.example.com' --expires-before 30d
+  step-badger x509Certs --ca-config /etc/step-ca/config/ca.json`,
 
-	Args: cobra.ExactArgs(1),
+	Args: columnsArgs(databaseArgs(0)),
 
 	Run: func(cmd *cobra.Command, args []string) {
+		if config.listColumns {
+			emitX509ColumnList()
+			return
+		}
 		exportX509Main(args)
 	},
 }
@@ -54,14 +52,33 @@ func init() {
 	x509certsCmd.Flags().BoolVarP(&config.showValid, "valid", "v", true, "valid certificates shown")
 	x509certsCmd.Flags().BoolVarP(&config.showRevoked, "revoked", "r", false, "revoked certificates shown")
 	x509certsCmd.Flags().BoolVarP(&config.showExpired, "expired", "e", false, "expired certificates shown")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.subject, "match-subject", "", "subject regex")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.san, "match-san", "", "dns name, ip address, email address or uri glob")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.provisioner, "match-provisioner", "", "provisioner name glob")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.provisionerType, "match-provisioner-type", "", "provisioner type glob, e.g. ACME")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.serial, "match-serial", "", "serial number, decimal or hex")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.issuer, "match-issuer", "", "issuer regex")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.issuedAfter, "issued-after", "", "start after date or relative duration, e.g. 2026-01-01 or -30d")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.issuedBefore, "issued-before", "", "start before date or relative duration")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.expiresAfter, "expires-after", "", "finish after date or relative duration")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.expiresBefore, "expires-before", "", "finish before date or relative duration, e.g. 30d")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.revokedAfter, "revoked-after", "", "revoked after date or relative duration")
+	x509certsCmd.Flags().StringVar(&config.filterArgs.revokedBefore, "revoked-before", "", "revoked before date or relative duration")
 
 	// Format choice
 	x509certsCmd.Flags().Var(config.emitX509Format, "emit", "emit format: "+FORMAT_TABLE+"|"+FORMAT_JSON+"|"+FORMAT_MARKDOWN+
-		"|"+FORMAT_OPENSSL+"|"+FORMAT_PLAIN)
+		"|"+FORMAT_OPENSSL+"|"+FORMAT_PLAIN+"|"+FORMAT_PROMETHEUS+"|"+FORMAT_CSV+"|"+FORMAT_TSV+"|"+FORMAT_TEMPLATE+"|"+FORMAT_HTML+"|"+FORMAT_PEM)
+	x509certsCmd.Flags().BoolVar(&config.noHeader, "no-header", false, "header row omitted, csv and tsv only")
+	x509certsCmd.Flags().StringVar(&config.delimiter, "delimiter", "", "delimiter of csv and tsv, comma and tab by default")
+	x509certsCmd.Flags().StringVar(&config.templateFile, "template", "", "text/template file, template only")
+	x509certsCmd.Flags().StringVar(&config.outDir, "output-dir", "", "directory certificate files and manifest are written into, pem only")
+	x509certsCmd.Flags().Var(config.fileName, "file-name", "file named after: "+FILE_NAME_SERIAL+"|"+FILE_NAME_SUBJECT)
 	x509certsCmd.Flags().Var(config.timeFormat, "time", "time format: "+TIME_ISO+"|"+TIME_SHORT)
-	x509certsCmd.Flags().Var(config.sortOrder, "sort", "sort order: "+SORT_START+"|"+SORT_FINISH)
+	x509certsCmd.Flags().StringVar(&config.sortOrder, "sort", SORT_DEFAULT, "sort keys, column ids, - prefix for descending, e.g. provisioner,-finish")
 
 	// Columns selection criteria.
+	x509certsCmd.Flags().StringVar(&config.columns, "columns", "", "columns shown, in order, e.g. serial,subject,finish; or +id,-id tweaking the defaults")
+	x509certsCmd.Flags().BoolVar(&config.listColumns, "list-columns", false, "ids of columns listed")
 	x509certsCmd.Flags().BoolVar(&config.showSerial, "serial", true, "serial number column shown")
 	x509certsCmd.Flags().BoolVar(&config.showDNSNames, "dnsnames", false, "dns names column shown")
 	x509certsCmd.Flags().BoolVar(&config.showEmailAddresses, "emailaddresses", false, "email addresses column shown")
@@ -81,101 +98,54 @@ func exportX509Main(args []string) {
 
 	checkLogginglevel(args)
 
-	var (
-		err error
-		db  database.DB
+	var x509CertificatesProvisionersRevocations []tX509CertificateProvisionerRevocation
 
-		x509CertificateProvisionerRevocation    tX509CertificateProvisionerRevocation
-		x509CertificatesProvisionersRevocations []tX509CertificateProvisionerRevocation
-	)
+	// Parse record selection criteria.
+	filter, err := config.filterArgs.parse(time.Now())
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+	filter.validities = getSelectedValidities(config.showValid, config.showRevoked, config.showExpired)
 
-	// Open the database.
-	db, err = nosql.New("badgerv2", args[0], database.WithValueDir(args[0]))
+	// Validate columns selection, before the database is read.
+	getShownX509Columns()
+	sortKeys, err := getX509SortKeys(config.sortOrder)
 	if err != nil {
-		logError.Fatalln(err)
+		exitWithError(EXIT_FAILURE, err)
 	}
 
-	// Get records from the x509_certs bucket.
-	records, err := db.List([]byte("x509_certs"))
+	// Open the database.
+	dbConfig, _, err := getDbConfig(args)
 	if err != nil {
-		logError.Fatalln(err)
+		exitWithError(EXIT_DB_OPEN, err)
 	}
-	if records == nil {
-		logError.Fatalln("no records found")
+	reader, err := openReader(dbConfig)
+	if err != nil {
+		exitWithError(EXIT_DB_OPEN, err)
 	}
 
-	for _, record := range records {
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("Bucket: %s", record.Bucket)
-			logInfo.Printf("Key: %s", record.Key)
-			logInfo.Printf("Value: %q", record.Value)
-		}
-
-		// Get certificate.
-		x509Certificate := parseValueToX509Certificate(record.Value)
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("Serial: %s", x509Certificate.SerialNumber.String())
-			logInfo.Printf("Subject: %s", x509Certificate.Subject)
-		}
-
-		// Get revocation.
-		x509CertificateRevocation := getX509Revocation(db, x509Certificate)
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("RevocationProvisionerID: %s", x509CertificateRevocation.ProvisionerID)
-		}
-
-		// Get provisioner.
-		x509CertificateData := getX509CertificateData(db, x509Certificate)
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("Provisioner: %s", x509CertificateData.Provisioner.Type)
-		}
-
-		// Populate the child.
-		x509CertificateProvisionerRevocation = tX509CertificateProvisionerRevocation{
-			X509Certificate: x509Certificate,
-			X509Revocation:  x509CertificateRevocation,
-			X509Provisioner: x509CertificateData.Provisioner,
-		}
+	// Get certificates joined with revocation and provisioner.
+	x509Certificates, err := loadX509Certificates(reader, handleRecordError)
+	if err != nil {
+		reader.Close()
+		exitWithError(getBucketExitCode(err), err)
+	}
 
-		// Populate child validity info of the certificate.
-		if len(x509CertificateRevocation.ProvisionerID) > 0 && time.Now().After(x509CertificateRevocation.RevokedAt) {
-			x509CertificateProvisionerRevocation.Validity = REVOKED_STR
-		} else {
-			if time.Now().After(x509Certificate.NotAfter) {
-				x509CertificateProvisionerRevocation.Validity = EXPIRED_STR
-			} else {
-				x509CertificateProvisionerRevocation.Validity = VALID_STR
-			}
-		}
+	// Close the database.
+	if err = reader.Close(); err != nil {
+		logError.Fatalln(err)
+	}
 
-		// Append child into collection, if record selection criteria are met.
-		if (config.showExpired && x509CertificateProvisionerRevocation.Validity == EXPIRED_STR) ||
-			(config.showRevoked && x509CertificateProvisionerRevocation.Validity == REVOKED_STR) ||
-			(config.showValid && x509CertificateProvisionerRevocation.Validity == VALID_STR) {
+	// Append child into collection, if record selection criteria are met.
+	for _, x509CertificateProvisionerRevocation := range x509Certificates {
+		if filter.matchX509(x509CertificateProvisionerRevocation) {
 			x509CertificatesProvisionersRevocations = append(x509CertificatesProvisionersRevocations,
 				x509CertificateProvisionerRevocation)
 		}
-
-	}
-
-	// Close the database.
-	if err = db.Close(); err != nil {
-		logError.Fatalln(err)
 	}
 
 	// Sort.
-	switch thisSort := config.sortOrder.Value; thisSort {
-	case SORT_FINISH:
-		sort.SliceStable(x509CertificatesProvisionersRevocations, func(i, j int) bool {
-			return x509CertificatesProvisionersRevocations[i].X509Certificate.NotAfter.
-				Before(x509CertificatesProvisionersRevocations[j].X509Certificate.NotAfter)
-		})
-	case SORT_START:
-		sort.SliceStable(x509CertificatesProvisionersRevocations, func(i, j int) bool {
-			return x509CertificatesProvisionersRevocations[i].X509Certificate.NotBefore.
-				Before(x509CertificatesProvisionersRevocations[j].X509Certificate.NotBefore)
-		})
-	}
+	sortX509Certificates(x509CertificatesProvisionersRevocations, sortKeys)
 
 	// Output.
 	switch format := config.emitX509Format.Value; format {
@@ -189,115 +159,18 @@ func exportX509Main(args []string) {
 		emitX509OpenSsl(x509CertificatesProvisionersRevocations)
 	case FORMAT_PLAIN:
 		emitX509Plain(x509CertificatesProvisionersRevocations)
-	}
-}
-
-func getX509Revocation(thisDB database.DB, thisX509Certificate x509.Certificate) tCertificateRevocation {
-
-	revocationValue, err := thisDB.Get([]byte("revoked_x509_certs"), []byte(thisX509Certificate.SerialNumber.String()))
-
-	switch {
-	case errors.Is(err, database.ErrNotFound):
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("key for revocation not found")
-		}
-	case err != nil:
-		logInfo.Panic(err)
-	}
-
-	if loggingLevel >= 2 { // Show info.
-		logInfo.Printf("revocationValue: %s", revocationValue)
-	}
-
-	return parseValueToCertificateRevocation(revocationValue)
-}
-
-func getX509CertificateData(thisDB database.DB, thisX509Certificate x509.Certificate) tX509CertificateData {
-
-	certsDataValue, err := thisDB.Get([]byte("x509_certs_data"), []byte(thisX509Certificate.SerialNumber.String()))
-
-	switch {
-	case errors.Is(err, database.ErrNotFound):
-		if loggingLevel >= 2 { // Show info.
-			logInfo.Printf("key for certificate data not found")
-		}
-	case err != nil:
-		logInfo.Panic(err)
-	}
-
-	if loggingLevel >= 2 { // Show info.
-		logInfo.Printf("certsDataValue: %s", certsDataValue)
-	}
-
-	return parseValueToX509CertificateData(certsDataValue)
-}
-
-func parseValueToX509CertificateData(thisValue []byte) tX509CertificateData {
-
-	var (
-		certificateData tX509CertificateData
-	)
-
-	if len(strings.TrimSpace(string(thisValue))) > 0 {
-		if err := json.Unmarshal(thisValue, &certificateData); err != nil {
-			logError.Panic(err)
-		}
-	}
-	return certificateData
-}
-
-func parseValueToCertificateRevocation(thisValue []byte) tCertificateRevocation {
-
-	var (
-		certificateRevocation tCertificateRevocation
-	)
-
-	if len(strings.TrimSpace(string(thisValue))) > 0 {
-		if err := json.Unmarshal(thisValue, &certificateRevocation); err != nil {
-			logError.Panic(err)
-		}
-	}
-	return certificateRevocation
-}
-
-func parseValueToX509Certificate(thisValue []byte) x509.Certificate {
-
-	var (
-		x509Certificate *x509.Certificate
-	)
-
-	marshaledValue, err := json.Marshal(thisValue)
-	if err != nil {
-		logError.Panic(err)
-	}
-
-	if loggingLevel >= 2 { // Show info.
-		logInfo.Printf("marshaledValue: %s", marshaledValue)
-	}
-
-	// Adding header and footer.
-	pemBlockValue := makePEM(marshaledValue)
-
-	if loggingLevel >= 2 { // Show info.
-		logInfo.Printf("pemBlockValue: %s", pemBlockValue)
-	}
-
-	// Decode the PEM block.
-	decodedPEMBlock, _ := pem.Decode([]byte(pemBlockValue))
-	if decodedPEMBlock == nil {
-		logError.Panicf("failed to parse certificate PEM")
-	}
-
-	// Parse PEM block into certificate.
-	x509Certificate, err = x509.ParseCertificate(decodedPEMBlock.Bytes)
-	if err != nil {
-		logError.Panicf("failed to parse certificate: " + err.Error())
-	}
-
-	return *x509Certificate
-}
-
-func makePEM(thisValue []byte) string {
-	return fmt.Sprintf("-----BEGIN CERTIFICATE-----\n%s\n-----END CERTIFICATE-----",
-		strings.ReplaceAll(string(thisValue), "\"", ""))
+	case FORMAT_CSV, FORMAT_TSV:
+		emitX509Csv(x509CertificatesProvisionersRevocations, format)
+	case FORMAT_TEMPLATE:
+		emitTemplate(x509CertificatesProvisionersRevocations)
+	case FORMAT_HTML:
+		emitX509Html(x509CertificatesProvisionersRevocations)
+	case FORMAT_PROMETHEUS:
+		emitX509Prometheus(x509CertificatesProvisionersRevocations)
+	case FORMAT_PEM:
+		emitX509Pem(x509CertificatesProvisionersRevocations)
+	}
+
+	// Summary of skipped records.
+	reportRecordErrors()
 }
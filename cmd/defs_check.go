@@ -0,0 +1,42 @@
+package cmd
+
+import "time"
+
+/*
+Exit codes of the check command, following the Nagios plugin convention.
+*/
+const (
+	CHECK_OK       int = 0
+	CHECK_WARNING  int = 1
+	CHECK_CRITICAL int = 2
+	CHECK_UNKNOWN  int = 3
+)
+
+const (
+	CERTS_ALL  string = "all"
+	CERTS_X509 string = "x509"
+	CERTS_SSH  string = "ssh"
+)
+
+/*
+getCheckStatusStr maps given exit code of the check command to its status string.
+*/
+func getCheckStatusStr() map[int]string {
+	return map[int]string{
+		CHECK_OK:       "OK",
+		CHECK_WARNING:  "WARNING",
+		CHECK_CRITICAL: "CRITICAL",
+		CHECK_UNKNOWN:  "UNKNOWN",
+	}
+}
+
+/*
+Valid certificate evaluated against thresholds. Both ssh & x509.
+*/
+type tCheckedCertificate struct {
+	Kind   string    // Either CERTS_X509 or CERTS_SSH.
+	Serial string    // Decimal serial number.
+	Name   string    // Subject of x509, or principals of ssh certificate.
+	Finish time.Time // End of certificate's validity.
+	Status int       // One of CHECK_* codes.
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/smallstep/nosql/database"
+)
+
+/*
+Changes between two snapshots of the database.
+*/
+const (
+	CHANGE_ISSUED  string = "issued"
+	CHANGE_REVOKED string = "revoked"
+	CHANGE_EXPIRED string = "expired"
+	CHANGE_ADDED   string = "added"
+	CHANGE_REMOVED string = "removed"
+	CHANGE_CHANGED string = "changed"
+)
+
+/*
+Differences between two snapshots of the database.
+*/
+type tDiff struct {
+	OldPath string          `json:"OldPath"`
+	NewPath string          `json:"NewPath"`
+	OldTime time.Time       `json:"OldTime"` // Moment of the old snapshot, certificates expiring after it are newly expired.
+	NewTime time.Time       `json:"NewTime"` // Moment of the new snapshot.
+	X509    []tX509Change   `json:"X509"`
+	Ssh     []tSshChange    `json:"Ssh"`
+	Buckets []tBucketChange `json:"Buckets"`
+}
+
+/*
+Change of x509 certificate, shaped as in its json output with the change added.
+*/
+type tX509Change struct {
+	Change string `json:"Change"` // One of CHANGE_ISSUED, CHANGE_REVOKED or CHANGE_EXPIRED.
+	tX509CertificateProvisionerRevocation
+}
+
+/*
+Change of ssh certificate, shaped as in its json output with the change added.
+*/
+type tSshChange struct {
+	Change string `json:"Change"` // One of CHANGE_ISSUED, CHANGE_REVOKED or CHANGE_EXPIRED.
+	tSshCertificateWithRevocation
+}
+
+/*
+Change of entry in a bucket.
+*/
+type tBucketChange struct {
+	Change   string `json:"Change"` // One of CHANGE_ADDED, CHANGE_REMOVED or CHANGE_CHANGED.
+	Bucket   string `json:"Bucket"`
+	Key      []byte `json:"Key"`
+	OldValue []byte `json:"OldValue,omitempty"`
+	NewValue []byte `json:"NewValue,omitempty"`
+}
+
+/*
+Content of one snapshot being compared.
+*/
+type tDiffSnapshot struct {
+	x509Certificates []tX509CertificateProvisionerRevocation
+	sshCertificates  []tSshCertificateWithRevocation
+	entries          map[string][]*database.Entry // Raw entries by bucket.
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import "time"
+
+const MANIFEST_FILE string = "manifest.json"
+
+/*
+Manifest listing files written into output directory.
+*/
+type tManifest struct {
+	Generated time.Time       `json:"Generated"`
+	Format    string          `json:"Format"`
+	Files     []tManifestFile `json:"Files"`
+}
+
+/*
+File of a single certificate. Both ssh & x509.
+*/
+type tManifestFile struct {
+	File      string     `json:"File"`
+	Serial    string     `json:"Serial"`
+	Subject   string     `json:"Subject"`
+	Validity  string     `json:"Validity"`
+	NotBefore time.Time  `json:"NotBefore"`
+	NotAfter  *time.Time `json:"NotAfter"` // Null when valid forever.
+	Sha256    string     `json:"Sha256"`   // Hash of the file content.
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import "github.com/fatih/color"
+
+/*
+Data of the html report.
+*/
+type tHtmlReport struct {
+	Title     string
+	Generated string
+	Headers   []tHtmlHeader
+	Rows      []tHtmlRow
+	Colors    []tHtmlColor // Css classes of colors used by cells.
+}
+
+/*
+Header of the html report's column.
+*/
+type tHtmlHeader struct {
+	Title string
+	Align string // Css text-align, mirroring markdown alignment.
+}
+
+/*
+Single certificate of the html report.
+*/
+type tHtmlRow struct {
+	Cells   []tHtmlCell
+	Details []tHtmlDetail // Shown once the row is clicked.
+}
+
+/*
+Single cell of the html report.
+*/
+type tHtmlCell struct {
+	Text  string
+	Class string // Css class of cell's color.
+}
+
+/*
+Single detail of the certificate, with possibly many values.
+*/
+type tHtmlDetail struct {
+	Name   string
+	Values []string
+}
+
+/*
+Css class of color used by cells.
+*/
+type tHtmlColor struct {
+	Class string
+	Color string
+}
+
+/*
+getHtmlColor maps terminal color to css color, so that the report mirrors the table.
+*/
+func getHtmlColor() map[color.Attribute]string {
+	return map[color.Attribute]string{
+		color.FgWhite:    "#1f2328",
+		color.FgGreen:    "#1a7f37",
+		color.FgHiBlack:  "#8c959f",
+		color.FgHiYellow: "#9a6700",
+		color.FgCyan:     "#0969da",
+		color.FgMagenta:  "#8250df",
+	}
+}
+
+/*
+getHtmlAlign maps markdown alignment to css text-align.
+*/
+func getHtmlAlign() map[int]string {
+	return map[int]string{
+		ALIGN_LEFT:   "left",
+		ALIGN_CENTER: "center",
+		ALIGN_RIGHT:  "right",
+	}
+}
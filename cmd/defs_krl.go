@@ -0,0 +1,54 @@
+package cmd
+
+import "golang.org/x/crypto/ssh"
+
+/*
+OpenSSH key revocation list, as described in PROTOCOL.krl of OpenSSH.
+*/
+const (
+	KRL_MAGIC          uint64 = 0x5353484b524c0a00 // "SSHKRL\n\0"
+	KRL_FORMAT_VERSION uint32 = 1
+
+	KRL_SECTION_CERTIFICATES uint8 = 1
+
+	KRL_SECTION_CERT_SERIAL_LIST uint8 = 0x20
+	KRL_SECTION_CERT_KEY_ID      uint8 = 0x23
+)
+
+/*
+Header of KRL.
+*/
+type tKrlHeader struct {
+	Magic         uint64
+	FormatVersion uint32
+	KrlVersion    uint64
+	GeneratedDate uint64
+	Flags         uint64
+	Reserved      string
+	Comment       string
+}
+
+/*
+Section of KRL, also subsection of certificates section.
+*/
+type tKrlSection struct {
+	Type uint8
+	Data []byte
+}
+
+/*
+Header of certificates section, followed by its subsections.
+*/
+type tKrlCertificatesHeader struct {
+	CaKey    []byte
+	Reserved string
+}
+
+/*
+Revoked certificates signed by one CA key.
+*/
+type tKrlCertificates struct {
+	caKey   ssh.PublicKey   // Public key of the CA, signature key of the certificates.
+	serials map[uint64]bool // Revoked serial numbers.
+	keyIds  map[string]bool // Revoked key ids.
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestMarshalKrl(t *testing.T) {
+
+	// CA key of fixed bytes 0x01...0x20.
+	publicKey := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	for index := range publicKey {
+		publicKey[index] = byte(index + 1)
+	}
+	caKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	header := strings.Join([]string{
+		"5353484b524c0a00", // Magic "SSHKRL\n\0".
+		"00000001",         // Format version.
+		"0000000000000007", // KRL version.
+		"000000006553f100", // Generated date, 1700000000.
+		"0000000000000000", // Flags.
+		"00000000",         // Reserved.
+		"0000000474657374", // Comment "test".
+	}, "")
+
+	tests := []struct {
+		name            string
+		krlCertificates []*tKrlCertificates
+		want            string // Hex.
+	}{
+		{"no certificates", nil, header},
+		{"serials and key ids", []*tKrlCertificates{{
+			caKey:   caKey,
+			serials: map[uint64]bool{0x1234: true, 1: true},
+			keyIds:  map[string]bool{"bob": true, "alice": true},
+		}}, header + strings.Join([]string{
+			"01", "00000065", // Certificates section, 101 bytes.
+			"00000033",                       // CA key, 51 bytes.
+			"0000000b7373682d65643235353139", // "ssh-ed25519".
+			"000000200102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20", // Public key.
+			"00000000",       // Reserved.
+			"20", "00000010", // Serial list, 16 bytes.
+			"0000000000000001", "0000000000001234", // Sorted serials.
+			"23", "00000010", // Key ids, 16 bytes.
+			"00000005616c696365", "00000003626f62", // Sorted "alice", "bob".
+		}, "")},
+		{"serials only", []*tKrlCertificates{{
+			caKey:   caKey,
+			serials: map[uint64]bool{2: true},
+			keyIds:  map[string]bool{},
+		}}, header + strings.Join([]string{
+			"01", "00000048", // Certificates section, 72 bytes.
+			"00000033",
+			"0000000b7373682d65643235353139",
+			"000000200102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20",
+			"00000000",
+			"20", "00000008", // Serial list, 8 bytes.
+			"0000000000000002",
+		}, "")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := hex.DecodeString(test.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if krl := marshalKrl(test.krlCertificates, 7, now, "test"); !bytes.Equal(krl, want) {
+				t.Errorf("marshalKrl() =\n%x\nwant\n%x", krl, want)
+			}
+		})
+	}
+}
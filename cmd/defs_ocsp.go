@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"sync"
+	"time"
+)
+
+/*
+OCSP responder, with certificates cached between refreshes.
+*/
+type tOcspResponder struct {
+	issuer       *x509.Certificate // CA that issued the certificates.
+	responder    *x509.Certificate // Delegated responder, or the CA itself.
+	signer       crypto.Signer     // Private key of the responder.
+	validity     time.Duration     // Interval between this update and next update.
+	mutex        sync.RWMutex
+	certificates map[string]tX509CertificateProvisionerRevocation // Keyed by decimal serial number.
+	refreshedAt  time.Time                                        // Moment of last successful refresh, zero if none.
+	refreshErr   error                                            // Error of last refresh, nil if it succeeded.
+}
@@ -0,0 +1,33 @@
+package cmd
+
+const (
+	METRIC_PREFIX  string = "step_badger_"
+	METRIC_GAUGE   string = "gauge"
+	METRIC_COUNTER string = "counter"
+)
+
+/*
+Metric family of the Prometheus text exposition format.
+*/
+type tMetricFamily struct {
+	name    string // Name, without METRIC_PREFIX.
+	help    string
+	kind    string // Either METRIC_GAUGE or METRIC_COUNTER.
+	samples []tMetricSample
+}
+
+/*
+Single sample of metric family.
+*/
+type tMetricSample struct {
+	labels []tMetricLabel // Order is kept when emitted.
+	value  float64
+}
+
+/*
+Label of metric sample.
+*/
+type tMetricLabel struct {
+	name  string
+	value string
+}
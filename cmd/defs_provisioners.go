@@ -0,0 +1,29 @@
+package cmd
+
+import "time"
+
+/*
+Sources provisioners are known from.
+*/
+const (
+	PROVISIONER_FROM_DB           string = "database"
+	PROVISIONER_FROM_CA_CONFIG    string = "ca.json"
+	PROVISIONER_FROM_CERTIFICATES string = "certificates"
+)
+
+/*
+Provisioner with counts of x509 certificates it issued.
+*/
+type tProvisionerSummary struct {
+	ID          string    `json:"ID"`
+	Name        string    `json:"Name"`
+	Type        string    `json:"Type"`
+	Sources     []string  `json:"Sources"`           // Where the provisioner is known from, PROVISIONER_FROM_* values.
+	Deleted     bool      `json:"Deleted,omitempty"` // Deleted from the provisioners bucket.
+	Issued      int       `json:"Issued"`
+	Valid       int       `json:"Valid"`
+	Expired     int       `json:"Expired"`
+	Revoked     int       `json:"Revoked"`
+	FirstIssued time.Time `json:"FirstIssued"` // Earliest start of validity, zero if none issued.
+	LastIssued  time.Time `json:"LastIssued"`  // Latest start of validity, zero if none issued.
+}
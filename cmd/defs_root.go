@@ -4,22 +4,53 @@ import (
 	"log"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/fatih/color"
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
 )
 
 const (
 	MAX_LOGGING_LEVEL int    = 3 // Maximum allowed logging level.
 	TIME_SHORT        string = "short"
 	TIME_ISO          string = "iso"
-	SORT_START        string = "start"
-	SORT_FINISH       string = "finish"
+	SORT_DEFAULT      string = "finish"
 	FORMAT_TABLE      string = "table"
 	FORMAT_JSON       string = "json"
 	FORMAT_MARKDOWN   string = "markdown"
 	FORMAT_OPENSSL    string = "openssl"
 	FORMAT_PLAIN      string = "plain"
+	FORMAT_PROMETHEUS string = "prometheus"
+	FORMAT_CSV        string = "csv"
+	FORMAT_TSV        string = "tsv"
+	FORMAT_TEMPLATE   string = "template"
+	FORMAT_HTML       string = "html"
+	FORMAT_PEM        string = "pem"
+	FORMAT_OPENSSH    string = "openssh"
+	FORMAT_JSONL      string = "jsonl"
+	DB_AUTO           string = stepdb.TYPE_AUTO
+	DB_BADGERV1       string = stepdb.TYPE_BADGERV1
+	DB_BADGERV2       string = stepdb.TYPE_BADGERV2
+	DB_BBOLT          string = stepdb.TYPE_BBOLT
+)
+
+const (
+	CRL_PEM string = "pem"
+	CRL_DER string = "der"
+)
+
+const (
+	FILE_NAME_SERIAL  string = "serial"
+	FILE_NAME_SUBJECT string = "subject"
+)
+
+/*
+Exit codes, each failure class has its own.
+*/
+const (
+	EXIT_FAILURE        int = 1 // Generic failure, including invalid usage.
+	EXIT_DB_OPEN        int = 2 // Database could not be located or opened.
+	EXIT_BUCKET_MISSING int = 3 // Bucket not found, or holding no records.
+	EXIT_PARSE_ERROR    int = 4 // Malformed record found, in strict mode.
 )
 
 /*
@@ -36,36 +67,92 @@ func initLoggers() {
 }
 
 /*
-initChoices sets up Config struct for 'limited choice' flag.
+newConfig sets up Config struct with 'limited choice' flags. Package variable initialization runs it before any
+command registers its flags.
 */
-func initChoices() {
-	config.emitSshFormat = newChoice([]string{FORMAT_TABLE, FORMAT_JSON, FORMAT_MARKDOWN, FORMAT_PLAIN}, FORMAT_TABLE)
-	config.emitX509Format = newChoice([]string{FORMAT_TABLE, FORMAT_JSON, FORMAT_MARKDOWN, FORMAT_OPENSSL, FORMAT_PLAIN}, FORMAT_TABLE)
-	config.sortOrder = newChoice([]string{SORT_START, SORT_FINISH}, SORT_FINISH)
-	config.timeFormat = newChoice([]string{TIME_ISO, TIME_SHORT}, TIME_ISO)
+func newConfig() tConfig {
+	return tConfig{
+		emitSshFormat: newChoice([]string{FORMAT_TABLE, FORMAT_JSON, FORMAT_MARKDOWN, FORMAT_PLAIN,
+			FORMAT_PROMETHEUS, FORMAT_CSV, FORMAT_TSV, FORMAT_TEMPLATE, FORMAT_HTML, FORMAT_OPENSSH}, FORMAT_TABLE),
+		emitX509Format: newChoice([]string{FORMAT_TABLE, FORMAT_JSON, FORMAT_MARKDOWN, FORMAT_OPENSSL, FORMAT_PLAIN,
+			FORMAT_PROMETHEUS, FORMAT_CSV, FORMAT_TSV, FORMAT_TEMPLATE, FORMAT_HTML, FORMAT_PEM}, FORMAT_TABLE),
+		emitSummaryFormat: newChoice([]string{FORMAT_TABLE, FORMAT_JSON, FORMAT_MARKDOWN, FORMAT_PLAIN,
+			FORMAT_CSV, FORMAT_TSV}, FORMAT_TABLE),
+		emitStatsFormat: newChoice([]string{FORMAT_TABLE, FORMAT_JSON, FORMAT_MARKDOWN}, FORMAT_TABLE),
+		timeFormat:      newChoice([]string{TIME_ISO, TIME_SHORT}, TIME_ISO),
+		dbType:          newChoice([]string{DB_AUTO, DB_BADGERV1, DB_BADGERV2, DB_BBOLT}, DB_AUTO),
+		checkCerts:      newChoice([]string{CERTS_ALL, CERTS_X509, CERTS_SSH}, CERTS_ALL),
+		crlFormat:       newChoice([]string{CRL_PEM, CRL_DER}, CRL_PEM),
+		fileName:        newChoice([]string{FILE_NAME_SERIAL, FILE_NAME_SUBJECT}, FILE_NAME_SERIAL),
+		statsPeriod:     newChoice([]string{PERIOD_DAY, PERIOD_WEEK, PERIOD_MONTH}, PERIOD_MONTH),
+		emitWatchFormat: newChoice([]string{FORMAT_PLAIN, FORMAT_JSONL}, FORMAT_PLAIN),
+	}
 }
 
 /*
 Configuration structure.
 */
 type tConfig struct {
-	emitSshFormat      *tChoice
-	emitX509Format     *tChoice
-	showCrl            bool
-	showKeyId          bool
-	sortOrder          *tChoice
-	showValid          bool
-	showExpired        bool
-	showRevoked        bool
-	showProvisioner    bool
-	timeFormat         *tChoice
-	showDNSNames       bool
-	showEmailAddresses bool
-	showIPAddresses    bool
-	showURIs           bool
-	showIssuer         bool
-	showSerial         bool
-	showHostType       bool
+	emitSshFormat        *tChoice
+	emitX509Format       *tChoice
+	emitSummaryFormat    *tChoice
+	emitStatsFormat      *tChoice
+	showCrl              bool
+	showKeyId            bool
+	sortOrder            string
+	showValid            bool
+	showExpired          bool
+	showRevoked          bool
+	showProvisioner      bool
+	timeFormat           *tChoice
+	showDNSNames         bool
+	showEmailAddresses   bool
+	showIPAddresses      bool
+	showURIs             bool
+	showIssuer           bool
+	showSerial           bool
+	showHostType         bool
+	dbType               *tChoice
+	caConfig             string
+	readOnly             bool
+	snapshot             bool
+	strict               bool
+	filterArgs           tFilterArgs
+	checkCerts           *tChoice
+	checkWarning         string
+	checkCritical        string
+	serveListen          string
+	serveRefresh         string
+	noHeader             bool
+	delimiter            string
+	templateFile         string
+	caCert               string
+	caKey                string
+	caKeyPasswordFile    string
+	crlNextUpdate        string
+	crlNumber            string
+	crlDistributionPoint string
+	crlOmitExpired       bool
+	crlFormat            *tChoice
+	outFile              string
+	issuerCert           string
+	ocspValidity         string
+	ocspListen           string
+	outDir               string
+	krlKeyIds            bool
+	krlOmitExpired       bool
+	krlVersion           string
+	krlComment           string
+	fileName             *tChoice
+	columns              string
+	listColumns          bool
+	statsPeriod          *tChoice
+	statsExpiring        string
+	diffBuckets          string
+	diffOldTime          string
+	diffNewTime          string
+	emitWatchFormat      *tChoice
+	watchInterval        string
 }
 
 /*
@@ -80,17 +167,7 @@ const (
 /*
 Certificate revocation information. Both ssh & x509.
 */
-type tCertificateRevocation struct {
-	Serial        string    `json:"-"`
-	ProvisionerID string    `json:"ProvisionerID"`
-	ReasonCode    int       `json:"ReasonCode"`
-	Reason        string    `json:"Reason"`
-	RevokedAt     time.Time `json:"RevokedAt"`
-	ExpiresAt     time.Time `json:"ExpiresAt"`
-	TokenID       string    `json:"TokenID"`
-	MTLS          bool      `json:"MTLS"`
-	ACME          bool      `json:"ACME"`
-}
+type tCertificateRevocation = stepdb.Revocation
 
 const (
 	VALID_STR   string = "Valid"
@@ -98,6 +175,15 @@ const (
 	REVOKED_STR string = "Revoked"
 )
 
+/*
+Methods of revocation.
+*/
+const (
+	REVOKED_BY_ACME  string = "ACME"
+	REVOKED_BY_MTLS  string = "mTLS"
+	REVOKED_BY_TOKEN string = "token"
+)
+
 /*
 getValidityColor maps given status string to appropriate color.
 */
@@ -109,6 +195,24 @@ func getValidityColor() map[string]color.Attribute {
 	}
 }
 
+/*
+getRevocationReasonStr maps revocation reason code to its RFC 5280 name.
+*/
+func getRevocationReasonStr() map[int]string {
+	return map[int]string{
+		0:  "unspecified",
+		1:  "keyCompromise",
+		2:  "cACompromise",
+		3:  "affiliationChanged",
+		4:  "superseded",
+		5:  "cessationOfOperation",
+		6:  "certificateHold",
+		8:  "removeFromCRL",
+		9:  "privilegeWithdrawn",
+		10: "aACompromise",
+	}
+}
+
 /*
 getAlignChar amps given alignment to appropriate markdown string to be used in header separator.
 */
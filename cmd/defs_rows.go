@@ -0,0 +1,19 @@
+package cmd
+
+/*
+Generic table of rows, emitted by commands summarizing the database rather than listing certificates.
+*/
+type tRows struct {
+	header []string   // Titles of columns.
+	align  []int      // Alignment of each column, one of ALIGN_* values.
+	rows   [][]string // Content, one slice of cells per row.
+}
+
+/*
+appendRow adds row of given cells.
+
+	'thisCells' Cells of the row, one per column.
+*/
+func (thisRows *tRows) appendRow(thisCells ...string) {
+	thisRows.rows = append(thisRows.rows, thisCells)
+}
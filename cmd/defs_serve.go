@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+Parsed certificates cached by the serve command between refreshes.
+*/
+type tServeCache struct {
+	mutex           sync.RWMutex
+	x509            []tX509CertificateProvisionerRevocation
+	ssh             []tSshCertificateWithRevocation
+	malformed       int       // Malformed records skipped during last successful refresh.
+	refreshedAt     time.Time // Moment of last successful refresh, zero if none.
+	refreshErr      error     // Error of last refresh, nil if it succeeded.
+	refreshFailures int       // Number of failed refreshes since start.
+}
+
+/*
+Query parameter of the API, bound to a record selection criterion.
+*/
+type tServeParam struct {
+	name   string  // Name of the parameter, same as the name of command line flag.
+	target *string // Criterion set by the parameter.
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import "time"
+
+/*
+Periods of issuance counts.
+*/
+const (
+	PERIOD_DAY   string = "day"
+	PERIOD_WEEK  string = "week"
+	PERIOD_MONTH string = "month"
+)
+
+/*
+Statistics of the database. Kinds of certificates not selected are omitted.
+*/
+type tStats struct {
+	Generated time.Time   `json:"Generated"`
+	Period    string      `json:"Period"` // Period of issuance counts, one of PERIOD_* values.
+	X509      *tCertStats `json:"X509,omitempty"`
+	Ssh       *tCertStats `json:"Ssh,omitempty"`
+}
+
+/*
+Statistics of certificates of one kind. Both ssh & x509.
+*/
+type tCertStats struct {
+	Total          int            `json:"Total"`
+	ByValidity     []tStatsCount  `json:"ByValidity"`
+	ByProvisioner  []tStatsCount  `json:"ByProvisioner,omitempty"` // X509 only, ssh_certs does not record provisioner.
+	ByKeyAlgorithm []tStatsCount  `json:"ByKeyAlgorithm"`
+	ByType         []tStatsCount  `json:"ByType,omitempty"` // Ssh only, user or host.
+	Issuance       []tStatsCount  `json:"Issuance"`         // By period of start of validity, oldest first.
+	Lifetime       tLifetimeStats `json:"Lifetime"`
+	Expiring       []tStatsCount  `json:"Expiring"` // Valid certificates expiring within each window.
+}
+
+/*
+Count of certificates sharing a key, e.g. validity or period.
+*/
+type tStatsCount struct {
+	Key   string `json:"Key"`
+	Count int    `json:"Count"`
+}
+
+/*
+Distribution of total lifetime, in seconds. Ssh certificates valid forever are counted apart.
+*/
+type tLifetimeStats struct {
+	Min     int64 `json:"MinSeconds"`
+	Median  int64 `json:"MedianSeconds"`
+	P95     int64 `json:"P95Seconds"`
+	Max     int64 `json:"MaxSeconds"`
+	Forever int   `json:"Forever,omitempty"`
+}
+
+/*
+Properties of a single certificate the statistics are computed from. Both ssh & x509.
+*/
+type tStatsObservation struct {
+	validity     string
+	provisioner  string // Empty for ssh.
+	keyAlgorithm string
+	certType     string // Empty for x509.
+	start        time.Time
+	finish       time.Time
+	forever      bool // Ssh certificate valid forever, finish meaningless.
+}
+
+/*
+Titled table of statistics, as emitted in table and markdown formats.
+*/
+type tStatsSection struct {
+	title string
+	rows  tRows
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
+)
+
+/*
+Event of x509 certificate, shaped as in its json output with kind, change and its moment added.
+*/
+type tX509Event struct {
+	Kind string    `json:"Kind"` // Always CERTS_X509.
+	Time time.Time `json:"Time"` // Start of validity, revocation or end of validity.
+	tX509Change
+}
+
+/*
+Event of ssh certificate, shaped as in its json output with kind, change and its moment added.
+*/
+type tSshEvent struct {
+	Kind string    `json:"Kind"` // Always CERTS_SSH.
+	Time time.Time `json:"Time"` // Start of validity, revocation or end of validity.
+	tSshChange
+}
+
+/*
+Watcher of the database, keeping certificates of the last successful read.
+*/
+type tWatcher struct {
+	dbConfig stepdb.Config
+	x509     []tX509CertificateProvisionerRevocation
+	ssh      []tSshCertificateWithRevocation
+	readAt   time.Time // Moment of the last successful read, zero before the first one.
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"crypto/x509"
+
+	"github.com/lukasz-lobocki/step-badger/pkg/stepdb"
 )
 
 /*
@@ -15,18 +17,67 @@ type tX509CertificateProvisionerRevocation struct {
 }
 
 /*
-Intermediate structure to store certificate provisioner information.
+Certificate provisioner information.
+*/
+type tX509CertificateProvisioner = stepdb.Provisioner
+
+/*
+getExtensionStr maps object identifier of x509 extension to its name.
+*/
+func getExtensionStr() map[string]string {
+	return map[string]string{
+		"2.5.29.14":                   "Subject Key Identifier",
+		"2.5.29.15":                   "Key Usage",
+		"2.5.29.17":                   "Subject Alternative Name",
+		"2.5.29.19":                   "Basic Constraints",
+		"2.5.29.30":                   "Name Constraints",
+		"2.5.29.31":                   "CRL Distribution Points",
+		"2.5.29.32":                   "Certificate Policies",
+		"2.5.29.35":                   "Authority Key Identifier",
+		"2.5.29.37":                   "Extended Key Usage",
+		"1.3.6.1.5.5.7.1.1":           "Authority Information Access",
+		"1.3.6.1.4.1.11129.2.4.2":     "Signed Certificate Timestamps",
+		"1.3.6.1.4.1.37476.9000.64.1": "Step Provisioner",
+		"1.3.6.1.5.5.7.1.24":          "TLS Feature",
+		"1.3.6.1.4.1.311.20.2":        "Certificate Template Name",
+	}
+}
+
+/*
+getKeyUsageStr maps bit of x509 key usage to its RFC 5280 name.
 */
-type tX509CertificateData struct {
-	Provisioner tX509CertificateProvisioner `json:"Provisioner,omitempty"`
-	RaInfo      *string                     `json:"-"`
+func getKeyUsageStr() map[x509.KeyUsage]string {
+	return map[x509.KeyUsage]string{
+		x509.KeyUsageDigitalSignature:  "digitalSignature",
+		x509.KeyUsageContentCommitment: "contentCommitment",
+		x509.KeyUsageKeyEncipherment:   "keyEncipherment",
+		x509.KeyUsageDataEncipherment:  "dataEncipherment",
+		x509.KeyUsageKeyAgreement:      "keyAgreement",
+		x509.KeyUsageCertSign:          "keyCertSign",
+		x509.KeyUsageCRLSign:           "cRLSign",
+		x509.KeyUsageEncipherOnly:      "encipherOnly",
+		x509.KeyUsageDecipherOnly:      "decipherOnly",
+	}
 }
 
 /*
-Certificate provisioner information.
+getExtKeyUsageStr maps x509 extended key usage to its name.
 */
-type tX509CertificateProvisioner struct {
-	ID   string `json:"ID"`
-	Name string `json:"Name"`
-	Type string `json:"Type"`
+func getExtKeyUsageStr() map[x509.ExtKeyUsage]string {
+	return map[x509.ExtKeyUsage]string{
+		x509.ExtKeyUsageAny:                            "any",
+		x509.ExtKeyUsageServerAuth:                     "serverAuth",
+		x509.ExtKeyUsageClientAuth:                     "clientAuth",
+		x509.ExtKeyUsageCodeSigning:                    "codeSigning",
+		x509.ExtKeyUsageEmailProtection:                "emailProtection",
+		x509.ExtKeyUsageIPSECEndSystem:                 "ipsecEndSystem",
+		x509.ExtKeyUsageIPSECTunnel:                    "ipsecTunnel",
+		x509.ExtKeyUsageIPSECUser:                      "ipsecUser",
+		x509.ExtKeyUsageTimeStamping:                   "timeStamping",
+		x509.ExtKeyUsageOCSPSigning:                    "OCSPSigning",
+		x509.ExtKeyUsageMicrosoftServerGatedCrypto:     "msSGC",
+		x509.ExtKeyUsageNetscapeServerGatedCrypto:      "nsSGC",
+		x509.ExtKeyUsageMicrosoftCommercialCodeSigning: "msCodeCom",
+		x509.ExtKeyUsageMicrosoftKernelCodeSigning:     "msKernelCode",
+	}
 }
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+/*
+newCsvWriter creates RFC 4180 writer with delimiter given by --delimiter, defaulting to comma for csv and tab for tsv.
+
+	'thisWriter' Destination.
+	'thisFormat' Either FORMAT_CSV or FORMAT_TSV.
+*/
+func newCsvWriter(thisWriter io.Writer, thisFormat string) (*csv.Writer, error) {
+
+	delimiter := config.delimiter
+	switch {
+	case len(delimiter) == 0 && thisFormat == FORMAT_TSV:
+		delimiter = "\t"
+	case len(delimiter) == 0:
+		delimiter = ","
+	case delimiter == `\t` || delimiter == "tab":
+		delimiter = "\t"
+	}
+
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || comma == '"' || comma == '\r' || comma == '\n' || comma == utf8.RuneError {
+		return nil, fmt.Errorf("invalid delimiter %q, single character other than quote or line break expected", config.delimiter)
+	}
+
+	csvWriter := csv.NewWriter(thisWriter)
+	csvWriter.Comma = comma
+
+	return csvWriter, nil
+}
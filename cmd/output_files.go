@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Characters not kept in file names.
+var fileNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._@-]+`)
+
+/*
+emitX509Pem writes PEM encoded certificates, each into its own file named <serial>.crt or <subject>.crt, along with
+the manifest. Without output directory, certificates are printed as PEM bundle.
+
+	'thisX509CertsWithRevocations' Slice of certs.
+*/
+func emitX509Pem(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) {
+
+	var files []tManifestFile
+	names := make(map[string]bool)
+
+	for _, x509CertWithRevocation := range thisX509CertsWithRevocations {
+		certificate := x509CertWithRevocation.X509Certificate
+		content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+
+		if len(config.outDir) == 0 {
+			os.Stdout.Write(content)
+			continue
+		}
+
+		serial := certificate.SerialNumber.String()
+		file := getCertificateFileName(serial, certificate.Subject.CommonName, ".crt", names)
+
+		files = append(files, tManifestFile{
+			File:      file,
+			Serial:    serial,
+			Subject:   certificate.Subject.String(),
+			Validity:  x509CertWithRevocation.Validity,
+			NotBefore: certificate.NotBefore,
+			NotAfter:  &certificate.NotAfter,
+			Sha256:    getSha256Hex(content),
+		})
+		if err := writeCertificateFile(file, content); err != nil {
+			exitWithError(EXIT_FAILURE, err)
+		}
+	}
+
+	writeManifest(FORMAT_PEM, files)
+}
+
+/*
+emitSshCertsOpenSsh writes certificates in OpenSSH format, each into its own file named <serial>-cert.pub or
+<key id>-cert.pub, along with the manifest. Without output directory, certificates are printed one per line.
+
+	'thisSshCertificatesWithRevocations' Slice of certs.
+*/
+func emitSshCertsOpenSsh(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation) {
+
+	var files []tManifestFile
+	names := make(map[string]bool)
+
+	for _, sshCertificateWithRevocation := range thisSshCertificatesWithRevocations {
+		certificate := sshCertificateWithRevocation.SshCertificate
+		content := ssh.MarshalAuthorizedKey(&certificate)
+
+		if len(config.outDir) == 0 {
+			os.Stdout.Write(content)
+			continue
+		}
+
+		serial := strconv.FormatUint(certificate.Serial, 10)
+		subject := certificate.KeyId
+		if len(subject) == 0 && len(certificate.ValidPrincipals) > 0 {
+			subject = certificate.ValidPrincipals[0]
+		}
+		file := getCertificateFileName(serial, subject, "-cert.pub", names)
+
+		var notAfter *time.Time
+		if finish := getSshFinish(certificate); !finish.IsZero() {
+			finish = finish.UTC()
+			notAfter = &finish
+		}
+
+		files = append(files, tManifestFile{
+			File:      file,
+			Serial:    serial,
+			Subject:   strings.Join(certificate.ValidPrincipals, ","),
+			Validity:  sshCertificateWithRevocation.Validity,
+			NotBefore: time.Unix(int64(certificate.ValidAfter), 0).UTC(),
+			NotAfter:  notAfter,
+			Sha256:    getSha256Hex(content),
+		})
+		if err := writeCertificateFile(file, content); err != nil {
+			exitWithError(EXIT_FAILURE, err)
+		}
+	}
+
+	writeManifest(FORMAT_OPENSSH, files)
+}
+
+/*
+getCertificateFileName names file of a certificate, by serial or by subject as --file-name says. Subject falls back
+to serial when empty, and is suffixed with serial when already taken.
+
+	'thisSerial' Decimal serial number.
+	'thisSubject' Subject the file is named after, empty if none.
+	'thisSuffix' Suffix, with extension.
+	'thisTaken' Names already given, updated.
+*/
+func getCertificateFileName(thisSerial string, thisSubject string, thisSuffix string, thisTaken map[string]bool) string {
+
+	name := thisSerial
+	if config.fileName.Value == FILE_NAME_SUBJECT {
+		subject := strings.TrimLeft(fileNameUnsafe.ReplaceAllString(thisSubject, "_"), ".")
+		if len(subject) > 0 {
+			name = subject
+			if thisTaken[name] {
+				name = subject + "-" + thisSerial
+			}
+		}
+	}
+	thisTaken[name] = true
+
+	return name + thisSuffix
+}
+
+/*
+writeCertificateFile writes given content into output directory, creating it if needed.
+
+	'thisFile' Name of the file.
+	'thisContent' Content to be written.
+*/
+func writeCertificateFile(thisFile string, thisContent []byte) error {
+
+	if err := os.MkdirAll(config.outDir, 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(config.outDir, thisFile), thisContent, 0644)
+}
+
+/*
+writeManifest writes manifest listing given files into output directory. Nothing is written without output directory.
+
+	'thisFormat' Format of the files.
+	'thisFiles' Files written.
+*/
+func writeManifest(thisFormat string, thisFiles []tManifestFile) {
+
+	if len(config.outDir) == 0 {
+		return
+	}
+
+	if err := os.MkdirAll(config.outDir, 0755); err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	file, err := os.Create(filepath.Join(config.outDir, MANIFEST_FILE))
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+	defer file.Close()
+
+	if thisFiles == nil {
+		thisFiles = []tManifestFile{}
+	}
+	if err := writeJson(file, tManifest{Generated: time.Now().UTC(), Format: thisFormat, Files: thisFiles}); err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	if loggingLevel >= 1 { // Show info.
+		logInfo.Printf("%d files written into %s", len(thisFiles), config.outDir)
+	}
+}
+
+/*
+getSha256Hex returns hex encoded SHA-256 hash of given content.
+
+	'thisContent' Content to be hashed.
+*/
+func getSha256Hex(thisContent []byte) string {
+	hash := sha256.Sum256(thisContent)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+/*
+emitX509Html prints result in the form of self-contained html report.
+
+	'thisX509CertsWithRevocations' Slice of certs.
+*/
+func emitX509Html(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) {
+
+	columns := getShownX509Columns()
+	report := tHtmlReport{Title: "x509 certificates"}
+
+	// Building slice of titles.
+	for _, column := range columns {
+		report.Headers = append(report.Headers, tHtmlHeader{column.title(), getHtmlAlign()[column.contentAlignMD]})
+	}
+
+	// Iterating through certs.
+	for _, x509CertWithRevocation := range thisX509CertsWithRevocations {
+
+		var row tHtmlRow
+		for _, column := range columns {
+			row.Cells = append(row.Cells, tHtmlCell{
+				Text:  column.contentSource(x509CertWithRevocation, config),
+				Class: getHtmlColorClass(column.contentColor(x509CertWithRevocation)),
+			})
+		}
+		row.Details = getX509HtmlDetails(x509CertWithRevocation)
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	writeHtml(os.Stdout, report)
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows printed.\n", len(thisX509CertsWithRevocations))
+	}
+}
+
+/*
+emitSshCertsHtml prints result in the form of self-contained html report.
+
+	'thisSshCertificatesWithRevocations' Slice of certs.
+*/
+func emitSshCertsHtml(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation) {
+
+	columns := getShownSshColumns()
+	report := tHtmlReport{Title: "ssh certificates"}
+
+	// Building slice of titles.
+	for _, column := range columns {
+		report.Headers = append(report.Headers, tHtmlHeader{column.title(), getHtmlAlign()[column.contentAlignMD]})
+	}
+
+	// Iterating through certs.
+	for _, sshCertificateWithRevocation := range thisSshCertificatesWithRevocations {
+
+		var row tHtmlRow
+		for _, column := range columns {
+			row.Cells = append(row.Cells, tHtmlCell{
+				Text:  column.contentSource(sshCertificateWithRevocation, config),
+				Class: getHtmlColorClass(column.contentColor(sshCertificateWithRevocation)),
+			})
+		}
+		row.Details = getSshHtmlDetails(sshCertificateWithRevocation)
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	writeHtml(os.Stdout, report)
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows printed.\n", len(thisSshCertificatesWithRevocations))
+	}
+}
+
+/*
+getX509HtmlDetails lists full details of x509 certificate, empty ones omitted.
+
+	'thisX509CertWithRevocation' Certificate.
+*/
+func getX509HtmlDetails(thisX509CertWithRevocation tX509CertificateProvisionerRevocation) []tHtmlDetail {
+
+	certificate := thisX509CertWithRevocation.X509Certificate
+
+	var ipAddresses, uris, extensions []string
+	for _, ipAddress := range certificate.IPAddresses {
+		ipAddresses = append(ipAddresses, ipAddress.String())
+	}
+	for _, uri := range certificate.URIs {
+		uris = append(uris, uri.String())
+	}
+	for _, extension := range certificate.Extensions {
+		name := extension.Id.String()
+		if extensionStr, ok := getExtensionStr()[name]; ok {
+			name = extensionStr + " (" + name + ")"
+		}
+		if extension.Critical {
+			name += ", critical"
+		}
+		extensions = append(extensions, name)
+	}
+
+	details := []tHtmlDetail{
+		{"Subject", []string{certificate.Subject.String()}},
+		{"Issuer", []string{certificate.Issuer.String()}},
+		{"Serial number", []string{certificate.SerialNumber.String(), fmt.Sprintf("%X", certificate.SerialNumber)}},
+		{"Not before", []string{certificate.NotBefore.UTC().Format(time.RFC3339)}},
+		{"Not after", []string{certificate.NotAfter.UTC().Format(time.RFC3339)}},
+		{"DNS names", certificate.DNSNames},
+		{"Email addresses", certificate.EmailAddresses},
+		{"IP addresses", ipAddresses},
+		{"URIs", uris},
+		{"CRL distribution points", certificate.CRLDistributionPoints},
+		{"Extensions", extensions},
+	}
+
+	if provisioner := thisX509CertWithRevocation.X509Provisioner; len(provisioner.Name) > 0 {
+		details = append(details, tHtmlDetail{"Provisioner", []string{provisioner.Type + " " + provisioner.Name, provisioner.ID}})
+	}
+
+	return append(details, getRevocationHtmlDetails(thisX509CertWithRevocation.X509Revocation)...)
+}
+
+/*
+getSshHtmlDetails lists full details of ssh certificate, empty ones omitted.
+
+	'thisSshCertificateWithRevocation' Certificate.
+*/
+func getSshHtmlDetails(thisSshCertificateWithRevocation tSshCertificateWithRevocation) []tHtmlDetail {
+
+	certificate := thisSshCertificateWithRevocation.SshCertificate
+
+	validBefore := "forever"
+	if finish := getSshFinish(certificate); !finish.IsZero() {
+		validBefore = finish.UTC().Format(time.RFC3339)
+	}
+
+	details := []tHtmlDetail{
+		{"Key ID", []string{certificate.KeyId}},
+		{"Valid principals", certificate.ValidPrincipals},
+		{"Type", []string{getCertType()[int(certificate.CertType)]}},
+		{"Serial number", []string{strconv.FormatUint(certificate.Serial, 10), fmt.Sprintf("%X", certificate.Serial)}},
+		{"Valid after", []string{time.Unix(int64(certificate.ValidAfter), 0).UTC().Format(time.RFC3339)}},
+		{"Valid before", []string{validBefore}},
+		{"Critical options", getSshOptions(certificate.CriticalOptions)},
+		{"Extensions", getSshOptions(certificate.Extensions)},
+	}
+	if certificate.Key != nil {
+		details = append(details, tHtmlDetail{"Key type", []string{certificate.Key.Type()}})
+	}
+	if certificate.SignatureKey != nil {
+		details = append(details, tHtmlDetail{"Signature key type", []string{certificate.SignatureKey.Type()}})
+	}
+
+	return append(details, getRevocationHtmlDetails(thisSshCertificateWithRevocation.SshCertificateRevocation)...)
+}
+
+/*
+getRevocationHtmlDetails lists details of revocation, none if not revoked.
+
+	'thisRevocation' Revocation of the certificate.
+*/
+func getRevocationHtmlDetails(thisRevocation tCertificateRevocation) []tHtmlDetail {
+
+	if !thisRevocation.IsRevoked() {
+		return nil
+	}
+
+	return []tHtmlDetail{
+		{"Revoked at", []string{thisRevocation.RevokedAt.UTC().Format(time.RFC3339)}},
+		{"Revocation reason code", []string{getRevocationReasonCodeStr(thisRevocation)}},
+		{"Revocation reason", []string{thisRevocation.Reason}},
+		{"Revoking provisioner", []string{thisRevocation.ProvisionerID}},
+		{"Revocation method", []string{getRevocationMethod(thisRevocation)}},
+		{"Revocation token ID", []string{thisRevocation.TokenID}},
+	}
+}
+
+/*
+getHtmlColorClass returns css class of given terminal color.
+
+	'thisColor' Color of the cell.
+*/
+func getHtmlColorClass(thisColor color.Attribute) string {
+	return fmt.Sprintf("c%d", thisColor)
+}
+
+/*
+writeHtml writes the report as single html file, css and js inlined.
+
+	'thisWriter' Destination.
+	'thisReport' Report with headers and rows populated.
+*/
+func writeHtml(thisWriter io.Writer, thisReport tHtmlReport) {
+
+	thisReport.Generated = time.Now().UTC().Format(time.RFC3339)
+
+	// Css classes of all known colors.
+	for attribute, cssColor := range getHtmlColor() {
+		thisReport.Colors = append(thisReport.Colors, tHtmlColor{getHtmlColorClass(attribute), cssColor})
+	}
+	sort.Slice(thisReport.Colors, func(i, j int) bool { return thisReport.Colors[i].Class < thisReport.Colors[j].Class })
+
+	// Empty details are omitted.
+	for i := range thisReport.Rows {
+		var details []tHtmlDetail
+		for _, detail := range thisReport.Rows[i].Details {
+			if len(strings.Join(detail.Values, "")) > 0 {
+				details = append(details, detail)
+			}
+		}
+		thisReport.Rows[i].Details = details
+	}
+
+	report := template.Must(template.New("report").Parse(HTML_TEMPLATE))
+	if err := report.Execute(thisWriter, thisReport); err != nil {
+		logError.Panic(err)
+	}
+}
+
+/*
+Template of the html report.
+*/
+const HTML_TEMPLATE string = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; color: #1f2328; margin: 1.5em; }
+h1 { font-size: 1.4em; margin: 0 0 .2em; }
+.meta { color: #8c959f; margin-bottom: 1em; }
+#search { width: 24em; padding: .3em; margin-bottom: .8em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: .35em .6em; border-bottom: 1px solid #d0d7de; vertical-align: top; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; white-space: nowrap; position: sticky; top: 0; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr.filters th { cursor: default; top: 2.1em; }
+tr.filters input { width: 100%; box-sizing: border-box; }
+tr.row { cursor: pointer; }
+tr.row:hover { background: #f6f8fa; }
+tr.details td { background: #fbfcfd; }
+tr.details dl { display: grid; grid-template-columns: max-content auto; gap: .2em 1.2em; margin: .3em 0; }
+tr.details dt { font-weight: bold; }
+tr.details dd { margin: 0; font-family: ui-monospace, monospace; word-break: break-all; }
+{{range .Colors}}.{{.Class}} { color: {{.Color}}; }
+{{end}}</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">Generated {{.Generated}} by step-badger, <span id="count">{{len .Rows}}</span> of {{len .Rows}} shown. Click a row for details.</div>
+<input id="search" type="search" placeholder="Search all fields">
+<table id="report">
+<thead>
+<tr class="titles">{{range $i, $h := .Headers}}<th data-column="{{$i}}" style="text-align: {{$h.Align}}">{{$h.Title}}</th>{{end}}</tr>
+<tr class="filters">{{range .Headers}}<th><input type="search" placeholder="Filter"></th>{{end}}</tr>
+</thead>
+<tbody>
+{{range .Rows}}<tr class="row">{{range .Cells}}<td class="{{.Class}}">{{.Text}}</td>{{end}}</tr>
+<tr class="details" hidden><td colspan="{{len .Cells}}"><dl>{{range .Details}}<dt>{{.Name}}</dt><dd>{{range $i, $v := .Values}}{{if $i}}<br>{{end}}{{$v}}{{end}}</dd>{{end}}</dl></td></tr>
+{{end}}</tbody>
+</table>
+<script>
+(function () {
+  var table = document.getElementById("report");
+  var body = table.tBodies[0];
+  var titles = table.querySelectorAll("tr.titles th");
+  var filters = table.querySelectorAll("tr.filters input");
+  var search = document.getElementById("search");
+  var count = document.getElementById("count");
+
+  function pairs() {
+    var rows = body.querySelectorAll("tr.row");
+    return Array.prototype.map.call(rows, function (row) { return [row, row.nextElementSibling]; });
+  }
+
+  function compare(a, b) {
+    if (/^\d+$/.test(a) && /^\d+$/.test(b)) {
+      return a.length - b.length || (a < b ? -1 : a > b ? 1 : 0);
+    }
+    return a.localeCompare(b, undefined, { sensitivity: "base" });
+  }
+
+  titles.forEach(function (title) {
+    title.addEventListener("click", function () {
+      var column = Number(title.dataset.column);
+      var ascending = !title.classList.contains("asc");
+      titles.forEach(function (other) { other.classList.remove("asc", "desc"); });
+      title.classList.add(ascending ? "asc" : "desc");
+      pairs().sort(function (x, y) {
+        var result = compare(x[0].cells[column].textContent, y[0].cells[column].textContent);
+        return ascending ? result : -result;
+      }).forEach(function (pair) { body.appendChild(pair[0]); body.appendChild(pair[1]); });
+    });
+  });
+
+  function filter() {
+    var needles = Array.prototype.map.call(filters, function (input) { return input.value.toLowerCase(); });
+    var needle = search.value.toLowerCase();
+    var shown = 0;
+    pairs().forEach(function (pair) {
+      var match = needles.every(function (value, column) {
+        return !value || pair[0].cells[column].textContent.toLowerCase().indexOf(value) >= 0;
+      }) && (!needle || (pair[0].textContent + " " + pair[1].textContent).toLowerCase().indexOf(needle) >= 0);
+      pair[0].hidden = !match;
+      if (!match) { pair[1].hidden = true; }
+      if (match) { shown++; }
+    });
+    count.textContent = shown;
+  }
+
+  filters.forEach(function (input) { input.addEventListener("input", filter); });
+  search.addEventListener("input", filter);
+
+  body.addEventListener("click", function (event) {
+    var row = event.target.closest("tr.row");
+    if (row) { row.nextElementSibling.hidden = !row.nextElementSibling.hidden; }
+  });
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/*
+writeJson writes given value as indented json, followed by new line. Shared by json emitters and the API.
+
+	'thisWriter' Destination.
+	'thisValue' Value to be marshalled.
+*/
+func writeJson(thisWriter io.Writer, thisValue any) error {
+
+	jsonInfo, err := json.MarshalIndent(thisValue, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(thisWriter, string(jsonInfo))
+
+	return err
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+/*
+emitX509Prometheus prints result in the Prometheus text exposition format.
+
+	'thisX509CertsWithRevocations' Slice of certs.
+*/
+func emitX509Prometheus(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) {
+
+	writePrometheus(os.Stdout, getX509MetricFamilies(thisX509CertsWithRevocations))
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d records exposed.\n", len(thisX509CertsWithRevocations))
+	}
+}
+
+/*
+emitSshCertsPrometheus prints result in the Prometheus text exposition format.
+
+	'thisSshCertificatesWithRevocations' Slice of certs.
+*/
+func emitSshCertsPrometheus(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation) {
+
+	writePrometheus(os.Stdout, getSshMetricFamilies(thisSshCertificatesWithRevocations))
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d records exposed.\n", len(thisSshCertificatesWithRevocations))
+	}
+}
+
+/*
+getX509MetricFamilies builds per-certificate gauges and aggregates of x509 certificates.
+
+	'thisX509CertsWithRevocations' Slice of certs.
+*/
+func getX509MetricFamilies(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) []tMetricFamily {
+
+	notBefore := tMetricFamily{name: "x509_not_before_seconds", kind: METRIC_GAUGE,
+		help: "Start of x509 certificate's validity, in seconds since epoch."}
+	notAfter := tMetricFamily{name: "x509_not_after_seconds", kind: METRIC_GAUGE,
+		help: "End of x509 certificate's validity, in seconds since epoch."}
+	revokedAt := tMetricFamily{name: "x509_revoked_at_seconds", kind: METRIC_GAUGE,
+		help: "Revocation of x509 certificate, in seconds since epoch."}
+
+	byValidity := getValidityCounts()
+	byProvisioner := map[[3]string]float64{}
+	var revoked float64
+
+	for _, x509CertWithRevocation := range thisX509CertsWithRevocations {
+
+		certificate := x509CertWithRevocation.X509Certificate
+		labels := []tMetricLabel{
+			{"serial", certificate.SerialNumber.String()},
+			{"subject", certificate.Subject.String()},
+			{"provisioner", x509CertWithRevocation.X509Provisioner.Name},
+		}
+
+		notBefore.samples = append(notBefore.samples, tMetricSample{labels, float64(certificate.NotBefore.Unix())})
+		notAfter.samples = append(notAfter.samples, tMetricSample{labels, float64(certificate.NotAfter.Unix())})
+		if x509CertWithRevocation.X509Revocation.IsRevoked() {
+			revokedAt.samples = append(revokedAt.samples,
+				tMetricSample{labels, float64(x509CertWithRevocation.X509Revocation.RevokedAt.Unix())})
+			revoked++
+		}
+
+		byValidity[x509CertWithRevocation.Validity]++
+		byProvisioner[[3]string{x509CertWithRevocation.X509Provisioner.Name, x509CertWithRevocation.X509Provisioner.Type,
+			x509CertWithRevocation.Validity}]++
+	}
+
+	return []tMetricFamily{
+		notBefore,
+		notAfter,
+		revokedAt,
+		getValidityFamily("x509_certificates", "Number of x509 certificates by validity.", byValidity),
+		getGroupFamily("x509_certificates_by_provisioner", "Number of x509 certificates by provisioner and validity.",
+			[]string{"provisioner", "provisioner_type", "validity"}, byProvisioner),
+		{name: "x509_revoked_certificates", kind: METRIC_GAUGE, help: "Number of revoked x509 certificates selected.",
+			samples: []tMetricSample{{value: revoked}}},
+	}
+}
+
+/*
+getSshMetricFamilies builds per-certificate gauges and aggregates of ssh certificates.
+
+	'thisSshCertificatesWithRevocations' Slice of certs.
+*/
+func getSshMetricFamilies(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation) []tMetricFamily {
+
+	validAfter := tMetricFamily{name: "ssh_valid_after_seconds", kind: METRIC_GAUGE,
+		help: "Start of ssh certificate's validity, in seconds since epoch."}
+	validBefore := tMetricFamily{name: "ssh_valid_before_seconds", kind: METRIC_GAUGE,
+		help: "End of ssh certificate's validity, in seconds since epoch, +Inf if valid forever."}
+	revokedAt := tMetricFamily{name: "ssh_revoked_at_seconds", kind: METRIC_GAUGE,
+		help: "Revocation of ssh certificate, in seconds since epoch."}
+
+	byValidity := getValidityCounts()
+	byType := map[[3]string]float64{}
+	var revoked float64
+
+	for _, sshCertWithRevocation := range thisSshCertificatesWithRevocations {
+
+		certificate := sshCertWithRevocation.SshCertificate
+		labels := []tMetricLabel{
+			{"serial", strconv.FormatUint(certificate.Serial, 10)},
+			{"key_id", certificate.KeyId},
+			{"principals", strings.Join(certificate.ValidPrincipals, ",")},
+			{"type", getCertType()[int(certificate.CertType)]},
+		}
+
+		finish := float64(certificate.ValidBefore)
+		if certificate.ValidBefore == ssh.CertTimeInfinity {
+			finish = math.Inf(1)
+		}
+
+		validAfter.samples = append(validAfter.samples, tMetricSample{labels, float64(certificate.ValidAfter)})
+		validBefore.samples = append(validBefore.samples, tMetricSample{labels, finish})
+		if sshCertWithRevocation.SshCertificateRevocation.IsRevoked() {
+			revokedAt.samples = append(revokedAt.samples,
+				tMetricSample{labels, float64(sshCertWithRevocation.SshCertificateRevocation.RevokedAt.Unix())})
+			revoked++
+		}
+
+		byValidity[sshCertWithRevocation.Validity]++
+		byType[[3]string{getCertType()[int(certificate.CertType)], sshCertWithRevocation.Validity}]++
+	}
+
+	return []tMetricFamily{
+		validAfter,
+		validBefore,
+		revokedAt,
+		getValidityFamily("ssh_certificates", "Number of ssh certificates by validity.", byValidity),
+		getGroupFamily("ssh_certificates_by_type", "Number of ssh certificates by type and validity.",
+			[]string{"type", "validity"}, byType),
+		{name: "ssh_revoked_certificates", kind: METRIC_GAUGE, help: "Number of revoked ssh certificates selected.",
+			samples: []tMetricSample{{value: revoked}}},
+	}
+}
+
+/*
+getValidityCounts returns zeroed counts of every validity, so that absent ones are exposed too.
+*/
+func getValidityCounts() map[string]float64 {
+	return map[string]float64{VALID_STR: 0, EXPIRED_STR: 0, REVOKED_STR: 0}
+}
+
+/*
+getValidityFamily builds gauge of counts by validity, in fixed order.
+
+	'thisName' Name of the family.
+	'thisHelp' Help of the family.
+	'thisCounts' Counts by validity.
+*/
+func getValidityFamily(thisName string, thisHelp string, thisCounts map[string]float64) tMetricFamily {
+
+	family := tMetricFamily{name: thisName, help: thisHelp, kind: METRIC_GAUGE}
+	for _, validity := range []string{VALID_STR, EXPIRED_STR, REVOKED_STR} {
+		family.samples = append(family.samples,
+			tMetricSample{[]tMetricLabel{{"validity", validity}}, thisCounts[validity]})
+	}
+
+	return family
+}
+
+/*
+getGroupFamily builds gauge of counts by given labels, sorted by label values.
+
+	'thisName' Name of the family.
+	'thisHelp' Help of the family.
+	'thisLabelNames' Names of the labels, matching positions of the group key.
+	'thisCounts' Counts by group key.
+*/
+func getGroupFamily(thisName string, thisHelp string, thisLabelNames []string, thisCounts map[[3]string]float64) tMetricFamily {
+
+	keys := make([][3]string, 0, len(thisCounts))
+	for key := range thisCounts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.Join(keys[i][:], "\x00") < strings.Join(keys[j][:], "\x00")
+	})
+
+	family := tMetricFamily{name: thisName, help: thisHelp, kind: METRIC_GAUGE}
+	for _, key := range keys {
+		var labels []tMetricLabel
+		for i, labelName := range thisLabelNames {
+			labels = append(labels, tMetricLabel{labelName, key[i]})
+		}
+		family.samples = append(family.samples, tMetricSample{labels, thisCounts[key]})
+	}
+
+	return family
+}
+
+/*
+writePrometheus writes metric families in the Prometheus text exposition format, without timestamps,
+as expected by node_exporter's textfile collector.
+
+	'thisWriter' Destination.
+	'thisFamilies' Metric families, each written once with its HELP and TYPE.
+*/
+func writePrometheus(thisWriter io.Writer, thisFamilies []tMetricFamily) {
+
+	escapeHelp := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	escapeLabel := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+	for _, family := range thisFamilies {
+		name := METRIC_PREFIX + family.name
+
+		fmt.Fprintf(thisWriter, "# HELP %s %s\n", name, escapeHelp.Replace(family.help))
+		fmt.Fprintf(thisWriter, "# TYPE %s %s\n", name, family.kind)
+
+		for _, sample := range family.samples {
+			var labels []string
+			for _, label := range sample.labels {
+				labels = append(labels, fmt.Sprintf(`%s="%s"`, label.name, escapeLabel.Replace(label.value)))
+			}
+
+			if len(labels) > 0 {
+				fmt.Fprintf(thisWriter, "%s{%s} %s\n", name, strings.Join(labels, ","), formatMetricValue(sample.value))
+			} else {
+				fmt.Fprintf(thisWriter, "%s %s\n", name, formatMetricValue(sample.value))
+			}
+		}
+	}
+}
+
+/*
+formatMetricValue formats sample value, infinities as +Inf and -Inf.
+
+	'thisValue' Value of the sample.
+*/
+func formatMetricValue(thisValue float64) string {
+	switch {
+	case math.IsInf(thisValue, 1):
+		return "+Inf"
+	case math.IsInf(thisValue, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(thisValue, 'f', -1, 64)
+}
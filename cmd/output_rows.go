@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/lukasz-lobocki/tabby"
+)
+
+/*
+emitRowsTable prints rows in the form of a table.
+
+	'thisRows' Rows, with header.
+*/
+func emitRowsTable(thisRows tRows) {
+
+	table := new(tabby.Table)
+
+	// Building slice of titles.
+	var header []string
+	for _, title := range thisRows.header {
+		header = append(header, color.New(color.Bold).SprintFunc()(title))
+	}
+
+	// Set the header.
+	if err := table.SetHeader(header); err != nil {
+		logError.Panic("Setting header failed. %w", err)
+	}
+
+	// Populate the table.
+	for _, row := range thisRows.rows {
+		if err := table.AppendRow(row); err != nil {
+			logError.Panic(err)
+		}
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows appended.\n", len(thisRows.rows))
+	}
+
+	// Emit the table.
+	if loggingLevel >= 3 { // Show spacing.
+		table.Print(&tabby.Config{Spacing: "|", Padding: "."})
+	} else {
+		table.Print(nil)
+	}
+}
+
+/*
+emitRowsPlain prints rows in the plain form, tab separated.
+
+	'thisRows' Rows, with header.
+*/
+func emitRowsPlain(thisRows tRows) {
+
+	fmt.Println(strings.Join(thisRows.header, "\t"))
+
+	for _, row := range thisRows.rows {
+		fmt.Println(strings.Join(row, "\t"))
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows printed.\n", len(thisRows.rows))
+	}
+}
+
+/*
+emitRowsCsv prints rows in the form of csv or tsv, quoted as per RFC 4180.
+
+	'thisRows' Rows, with header.
+	'thisFormat' Either FORMAT_CSV or FORMAT_TSV.
+*/
+func emitRowsCsv(thisRows tRows, thisFormat string) {
+
+	csvWriter, err := newCsvWriter(os.Stdout, thisFormat)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	if !config.noHeader {
+		if err := csvWriter.Write(thisRows.header); err != nil {
+			logError.Panic(err)
+		}
+	}
+
+	if err := csvWriter.WriteAll(thisRows.rows); err != nil {
+		logError.Panic(err)
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows printed.\n", len(thisRows.rows))
+	}
+}
+
+/*
+emitRowsMarkdown prints rows in the form of markdown table.
+
+	'thisRows' Rows, with header.
+*/
+func emitRowsMarkdown(thisRows tRows) {
+
+	fmt.Println("| " + strings.Join(thisRows.header, " | ") + " |")
+
+	// Emit markdown line that separates header from body table.
+	var separator []string
+	for _, align := range thisRows.align {
+		separator = append(separator, getAlignChar()[align])
+	}
+	fmt.Println("| " + strings.Join(separator, " | ") + " |")
+
+	for _, row := range thisRows.rows {
+		// Numbers, being right aligned, are not escaped.
+		var cells []string
+		for index, cell := range row {
+			if thisRows.align[index] != ALIGN_RIGHT {
+				cell = escapeMarkdown(cell)
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Println("| " + strings.Join(cells, " | ") + " |")
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows printed.\n", len(thisRows.rows))
+	}
+}
+
+/*
+getTimeStr formats time as --time says, zero time gives empty string.
+
+	'thisTime' Time to be formatted.
+*/
+func getTimeStr(thisTime time.Time) string {
+
+	switch {
+	case thisTime.IsZero():
+		return ""
+	case config.timeFormat.Value == TIME_SHORT:
+		return thisTime.UTC().Format(time.DateOnly)
+	default:
+		return thisTime.UTC().Format(time.RFC3339)
+	}
+}
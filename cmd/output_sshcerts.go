@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
@@ -18,18 +18,16 @@ emitSshCertsTable prints result in the form of a table.
 func emitSshCertsTable(thisSshCerts []tSshCertificateWithRevocation) {
 
 	table := new(tabby.Table)
-	columns := getSshColumns()
+	columns := getShownSshColumns()
 
 	// Building slice of titles.
 	var header []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			header = append(header,
-				color.New(column.titleColor).SprintFunc()(
-					column.title(),
-				),
-			)
-		}
+		header = append(header,
+			color.New(column.titleColor).SprintFunc()(
+				column.title(),
+			),
+		)
 	}
 
 	// Set the header.
@@ -47,13 +45,11 @@ func emitSshCertsTable(thisSshCerts []tSshCertificateWithRevocation) {
 		// Building slice of columns within a single row.
 		var row []string
 		for _, column := range columns {
-			if column.isShown(config) {
-				row = append(row,
-					color.New(column.contentColor(sshCert)).SprintFunc()(
-						column.contentSource(sshCert, config),
-					),
-				)
-			}
+			row = append(row,
+				color.New(column.contentColor(sshCert)).SprintFunc()(
+					column.contentSource(sshCert, config),
+				),
+			)
 		}
 
 		if err := table.AppendRow(row); err != nil {
@@ -84,13 +80,10 @@ emitSshCertsJson prints result in the form of a json.
 */
 func emitSshCertsJson(thisSshCerts []tSshCertificateWithRevocation) {
 
-	jsonInfo, err := json.MarshalIndent(thisSshCerts, "", "  ")
-	if err != nil {
+	if err := writeJson(os.Stdout, thisSshCerts); err != nil {
 		logError.Panic(err)
 	}
 
-	fmt.Println(string(jsonInfo))
-
 	if loggingLevel >= 2 { // Show info.
 		logInfo.Printf("%d records marshalled.\n", len(thisSshCerts))
 	}
@@ -103,14 +96,12 @@ emitSshCertsPlain prints result in the plain form.
 */
 func emitSshCertsPlain(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation) {
 
-	columns := getSshColumns()
+	columns := getShownSshColumns()
 
 	// Building slice of titles.
 	var header []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			header = append(header, column.title())
-		}
+		header = append(header, column.title())
 	}
 
 	// Emitting titles.
@@ -126,9 +117,7 @@ func emitSshCertsPlain(thisSshCertificatesWithRevocations []tSshCertificateWithR
 		// Building slice of columns within a single row.
 		var row []string
 		for _, column := range columns {
-			if column.isShown(config) {
-				row = append(row, column.contentSource(sshCertificateWithRevocation, config))
-			}
+			row = append(row, column.contentSource(sshCertificateWithRevocation, config))
 		}
 
 		// Emitting row.
@@ -140,6 +129,61 @@ func emitSshCertsPlain(thisSshCertificatesWithRevocations []tSshCertificateWithR
 	}
 }
 
+/*
+emitSshCertsCsv prints result in the form of csv or tsv, quoted as per RFC 4180.
+
+	'thisSshCertificatesWithRevocations' Slice of certs.
+	'thisFormat' Either FORMAT_CSV or FORMAT_TSV.
+*/
+func emitSshCertsCsv(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation, thisFormat string) {
+
+	csvWriter, err := newCsvWriter(os.Stdout, thisFormat)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	columns := getShownSshColumns()
+
+	// Emitting titles.
+	if !config.noHeader {
+		var header []string
+		for _, column := range columns {
+			header = append(header, column.title())
+		}
+		if err := csvWriter.Write(header); err != nil {
+			logError.Panic(err)
+		}
+
+		if loggingLevel >= 1 { // Show info.
+			logInfo.Println("header printed.")
+		}
+	}
+
+	// Iterating through certs.
+	for _, sshCertificateWithRevocation := range thisSshCertificatesWithRevocations {
+
+		// Building slice of columns within a single row.
+		var row []string
+		for _, column := range columns {
+			row = append(row, column.contentSource(sshCertificateWithRevocation, config))
+		}
+
+		// Emitting row.
+		if err := csvWriter.Write(row); err != nil {
+			logError.Panic(err)
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		logError.Panic(err)
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows printed.\n", len(thisSshCertificatesWithRevocations))
+	}
+}
+
 /*
 emitSshCertsMarkdown prints result in the form of markdown table.
 
@@ -147,14 +191,12 @@ emitSshCertsMarkdown prints result in the form of markdown table.
 */
 func emitSshCertsMarkdown(thisSshCertificatesWithRevocations []tSshCertificateWithRevocation) {
 
-	columns := getSshColumns()
+	columns := getShownSshColumns()
 
 	// Building slice of titles.
 	var header []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			header = append(header, column.title())
-		}
+		header = append(header, column.title())
 	}
 
 	// Emitting titles.
@@ -167,9 +209,7 @@ func emitSshCertsMarkdown(thisSshCertificatesWithRevocations []tSshCertificateWi
 	// Emit markdown line that separates header from body table.
 	var separator []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			separator = append(separator, getAlignChar()[column.contentAlignMD])
-		}
+		separator = append(separator, getAlignChar()[column.contentAlignMD])
 	}
 	fmt.Println("| " + strings.Join(separator, " | ") + " |")
 
@@ -183,12 +223,10 @@ func emitSshCertsMarkdown(thisSshCertificatesWithRevocations []tSshCertificateWi
 		// Building slice of columns within a single row.
 		var row []string
 		for _, column := range columns {
-			if column.isShown(config) {
-				if column.contentEscapeMD {
-					row = append(row, escapeMarkdown(column.contentSource(sshCertificateWithRevocation, config)))
-				} else {
-					row = append(row, column.contentSource(sshCertificateWithRevocation, config))
-				}
+			if column.contentEscapeMD {
+				row = append(row, escapeMarkdown(column.contentSource(sshCertificateWithRevocation, config)))
+			} else {
+				row = append(row, column.contentSource(sshCertificateWithRevocation, config))
 			}
 		}
 
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+/*
+emitStatsTable prints statistics in the form of tables, each preceded by its title.
+
+	'thisSections' Titled tables.
+*/
+func emitStatsTable(thisSections []tStatsSection) {
+
+	for index, section := range thisSections {
+		if index > 0 {
+			fmt.Println()
+		}
+		fmt.Println(color.New(color.FgHiCyan).SprintFunc()(section.title))
+		emitRowsTable(section.rows)
+	}
+}
+
+/*
+emitStatsMarkdown prints statistics in the form of markdown tables, each preceded by its heading.
+
+	'thisSections' Titled tables.
+*/
+func emitStatsMarkdown(thisSections []tStatsSection) {
+
+	for index, section := range thisSections {
+		if index > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("### %s\n\n", escapeMarkdown(section.title))
+		emitRowsMarkdown(section.rows)
+	}
+}
+
+/*
+getStatsSections lays statistics out as titled tables, x509 first.
+
+	'thisStats' Statistics.
+*/
+func getStatsSections(thisStats tStats) []tStatsSection {
+
+	var sections []tStatsSection
+
+	for _, kind := range []struct {
+		name      string
+		certStats *tCertStats
+	}{{"X509", thisStats.X509}, {"SSH", thisStats.Ssh}} {
+		if kind.certStats == nil {
+			continue
+		}
+		certStats := kind.certStats
+
+		sections = append(sections,
+			tStatsSection{kind.name + " certificates by validity", getStatsCountRows("Validity", certStats.ByValidity)})
+		if kind.name == "X509" {
+			sections = append(sections,
+				tStatsSection{kind.name + " certificates by provisioner", getStatsCountRows("Provisioner", certStats.ByProvisioner)})
+		}
+		sections = append(sections,
+			tStatsSection{kind.name + " certificates by key algorithm", getStatsCountRows("Key algorithm", certStats.ByKeyAlgorithm)})
+		if kind.name == "SSH" {
+			sections = append(sections,
+				tStatsSection{kind.name + " certificates by type", getStatsCountRows("Type", certStats.ByType)})
+		}
+		sections = append(sections,
+			tStatsSection{kind.name + " certificates issued per " + thisStats.Period, getStatsCountRows("Period", certStats.Issuance)},
+			tStatsSection{kind.name + " certificates by lifetime", getLifetimeRows(certStats)},
+			tStatsSection{kind.name + " valid certificates expiring within", getStatsCountRows("Window", certStats.Expiring)},
+		)
+	}
+
+	return sections
+}
+
+/*
+getStatsCountRows lays counts out as rows of a table, in order given.
+
+	'thisTitle' Title of the key column.
+	'thisStatsCounts' Counts by key.
+*/
+func getStatsCountRows(thisTitle string, thisStatsCounts []tStatsCount) tRows {
+
+	rows := tRows{header: []string{thisTitle, "Count"}, align: []int{ALIGN_LEFT, ALIGN_RIGHT}}
+
+	for _, statsCount := range thisStatsCounts {
+		rows.appendRow(statsCount.Key, strconv.Itoa(statsCount.Count))
+	}
+
+	return rows
+}
+
+/*
+getLifetimeRows lays distribution of lifetime out as rows of a table. Empty distribution gives empty values.
+
+	'thisCertStats' Statistics of certificates of one kind.
+*/
+func getLifetimeRows(thisCertStats *tCertStats) tRows {
+
+	rows := tRows{header: []string{"Statistic", "Lifetime"}, align: []int{ALIGN_LEFT, ALIGN_RIGHT}}
+
+	finite := thisCertStats.Total > thisCertStats.Lifetime.Forever
+	lifetimeStr := func(thisSeconds int64) string {
+		if !finite {
+			return ""
+		}
+		return formatLifetime(time.Duration(thisSeconds) * time.Second)
+	}
+
+	rows.appendRow("Min", lifetimeStr(thisCertStats.Lifetime.Min))
+	rows.appendRow("Median", lifetimeStr(thisCertStats.Lifetime.Median))
+	rows.appendRow("P95", lifetimeStr(thisCertStats.Lifetime.P95))
+	rows.appendRow("Max", lifetimeStr(thisCertStats.Lifetime.Max))
+	if thisCertStats.Lifetime.Forever > 0 {
+		rows.appendRow("Forever", strconv.Itoa(thisCertStats.Lifetime.Forever))
+	}
+
+	return rows
+}
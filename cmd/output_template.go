@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+/*
+emitTemplate prints result rendered through the text/template given by --template.
+The template is executed with the slice of certs as its data.
+
+	'thisCerts' Slice of x509 or ssh certs.
+*/
+func emitTemplate(thisCerts any) {
+
+	if len(config.templateFile) == 0 {
+		exitWithError(EXIT_FAILURE, "--template is required with --emit "+FORMAT_TEMPLATE)
+	}
+
+	templateText, err := os.ReadFile(config.templateFile)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	userTemplate, err := template.New(config.templateFile).Funcs(getTemplateFuncs()).Parse(string(templateText))
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	if err := userTemplate.Execute(os.Stdout, thisCerts); err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d records rendered.\n", reflect.ValueOf(thisCerts).Len())
+	}
+}
+
+/*
+getTemplateFuncs defines helper functions available to templates.
+Times are accepted both as time.Time of x509 and as uint64 seconds of ssh.
+*/
+func getTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// formatTime formats time honoring --time, in UTC.
+		"formatTime": func(thisTime any) (string, error) {
+			if isForever(thisTime) {
+				return "forever", nil
+			}
+			moment, err := toTime(thisTime)
+			if err != nil {
+				return "", err
+			}
+			if config.timeFormat.Value == TIME_SHORT {
+				return moment.UTC().Format(time.DateOnly), nil
+			}
+			return moment.UTC().Format(time.RFC3339), nil
+		},
+
+		// join joins elements of any slice, e.g. DNSNames, IPAddresses or ValidPrincipals.
+		"join": func(thisList any, thisSeparator string) (string, error) {
+			list := reflect.ValueOf(thisList)
+			if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+				return "", fmt.Errorf("join: %T is not a list", thisList)
+			}
+			var elements []string
+			for i := 0; i < list.Len(); i++ {
+				elements = append(elements, fmt.Sprint(list.Index(i).Interface()))
+			}
+			return strings.Join(elements, thisSeparator), nil
+		},
+
+		// hexSerial formats serial number of x509 or ssh certificate as upper case hex.
+		"hexSerial": func(thisSerial any) (string, error) {
+			switch serial := thisSerial.(type) {
+			case *big.Int:
+				return fmt.Sprintf("%X", serial), nil
+			case uint64:
+				return fmt.Sprintf("%X", serial), nil
+			}
+			return "", fmt.Errorf("hexSerial: %T is not a serial number", thisSerial)
+		},
+
+		// daysUntil returns whole days left until given time, negative once passed.
+		"daysUntil": func(thisTime any) (int, error) {
+			if isForever(thisTime) {
+				return math.MaxInt32, nil
+			}
+			moment, err := toTime(thisTime)
+			if err != nil {
+				return 0, err
+			}
+			return int(math.Floor(time.Until(moment).Hours() / 24)), nil
+		},
+
+		"escapeMarkdown": escapeMarkdown,
+	}
+}
+
+/*
+toTime converts time of x509 or ssh certificate to time.Time.
+
+	'thisTime' Either time.Time or uint64 seconds since epoch.
+*/
+func toTime(thisTime any) (time.Time, error) {
+	switch moment := thisTime.(type) {
+	case time.Time:
+		return moment, nil
+	case uint64:
+		return time.Unix(int64(moment), 0), nil
+	}
+	return time.Time{}, fmt.Errorf("%T is not a time", thisTime)
+}
+
+/*
+isForever reports whether given time is the ssh certificate's infinity.
+
+	'thisTime' Either time.Time or uint64 seconds since epoch.
+*/
+func isForever(thisTime any) bool {
+	moment, ok := thisTime.(uint64)
+	return ok && moment == ssh.CertTimeInfinity
+}
@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -19,18 +19,16 @@ emitX509Table prints result in the form of a table.
 func emitX509Table(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) {
 
 	table := new(tabby.Table)
-	columns := getX509Columns()
+	columns := getShownX509Columns()
 
 	// Building slice of titles.
 	var header []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			header = append(header,
-				color.New(column.titleColor).SprintFunc()(
-					column.title(),
-				),
-			)
-		}
+		header = append(header,
+			color.New(column.titleColor).SprintFunc()(
+				column.title(),
+			),
+		)
 	}
 
 	// Set the header.
@@ -48,13 +46,11 @@ func emitX509Table(thisX509CertsWithRevocations []tX509CertificateProvisionerRev
 		// Building slice of columns within a single row.
 		var row []string
 		for _, column := range columns {
-			if column.isShown(config) {
-				row = append(row,
-					color.New(column.contentColor(x509CertWithRevocation)).SprintFunc()(
-						column.contentSource(x509CertWithRevocation, config),
-					),
-				)
-			}
+			row = append(row,
+				color.New(column.contentColor(x509CertWithRevocation)).SprintFunc()(
+					column.contentSource(x509CertWithRevocation, config),
+				),
+			)
 		}
 
 		if err := table.AppendRow(row); err != nil {
@@ -85,13 +81,10 @@ emitX509CertsWithRevocationsJson prints result in the form of a json.
 */
 func emitX509CertsWithRevocationsJson(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) {
 
-	jsonInfo, err := json.MarshalIndent(thisX509CertsWithRevocations, "", "  ")
-	if err != nil {
+	if err := writeJson(os.Stdout, thisX509CertsWithRevocations); err != nil {
 		logError.Panic(err)
 	}
 
-	fmt.Println(string(jsonInfo))
-
 	if loggingLevel >= 2 { // Show info.
 		logInfo.Printf("%d records marshalled.\n", len(thisX509CertsWithRevocations))
 	}
@@ -104,14 +97,12 @@ emitX509Plain prints result in the plain form.
 */
 func emitX509Plain(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) {
 
-	columns := getX509Columns()
+	columns := getShownX509Columns()
 
 	// Building slice of titles.
 	var header []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			header = append(header, column.title())
-		}
+		header = append(header, column.title())
 	}
 
 	// Emitting titles.
@@ -127,9 +118,7 @@ func emitX509Plain(thisX509CertsWithRevocations []tX509CertificateProvisionerRev
 		// Building slice of columns within a single row.
 		var row []string
 		for _, column := range columns {
-			if column.isShown(config) {
-				row = append(row, column.contentSource(x509CertWithRevocation, config))
-			}
+			row = append(row, column.contentSource(x509CertWithRevocation, config))
 		}
 
 		// Emitting row.
@@ -141,6 +130,61 @@ func emitX509Plain(thisX509CertsWithRevocations []tX509CertificateProvisionerRev
 	}
 }
 
+/*
+emitX509Csv prints result in the form of csv or tsv, quoted as per RFC 4180.
+
+	'thisX509CertsWithRevocations' Slice of certs.
+	'thisFormat' Either FORMAT_CSV or FORMAT_TSV.
+*/
+func emitX509Csv(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation, thisFormat string) {
+
+	csvWriter, err := newCsvWriter(os.Stdout, thisFormat)
+	if err != nil {
+		exitWithError(EXIT_FAILURE, err)
+	}
+
+	columns := getShownX509Columns()
+
+	// Emitting titles.
+	if !config.noHeader {
+		var header []string
+		for _, column := range columns {
+			header = append(header, column.title())
+		}
+		if err := csvWriter.Write(header); err != nil {
+			logError.Panic(err)
+		}
+
+		if loggingLevel >= 1 { // Show info.
+			logInfo.Println("header printed.")
+		}
+	}
+
+	// Iterating through certs.
+	for _, x509CertWithRevocation := range thisX509CertsWithRevocations {
+
+		// Building slice of columns within a single row.
+		var row []string
+		for _, column := range columns {
+			row = append(row, column.contentSource(x509CertWithRevocation, config))
+		}
+
+		// Emitting row.
+		if err := csvWriter.Write(row); err != nil {
+			logError.Panic(err)
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		logError.Panic(err)
+	}
+
+	if loggingLevel >= 2 { // Show info.
+		logInfo.Printf("%d rows printed.\n", len(thisX509CertsWithRevocations))
+	}
+}
+
 /*
 emitX509Markdown prints result in the form of markdown table.
 
@@ -148,14 +192,12 @@ emitX509Markdown prints result in the form of markdown table.
 */
 func emitX509Markdown(thisX509CertsWithRevocations []tX509CertificateProvisionerRevocation) {
 
-	columns := getX509Columns()
+	columns := getShownX509Columns()
 
 	// Building slice of titles.
 	var header []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			header = append(header, column.title())
-		}
+		header = append(header, column.title())
 	}
 
 	// Emitting titles.
@@ -168,9 +210,7 @@ func emitX509Markdown(thisX509CertsWithRevocations []tX509CertificateProvisioner
 	// Emit markdown line that separates header from body table.
 	var separator []string
 	for _, column := range columns {
-		if column.isShown(config) {
-			separator = append(separator, getAlignChar()[column.contentAlignMD])
-		}
+		separator = append(separator, getAlignChar()[column.contentAlignMD])
 	}
 	fmt.Println("| " + strings.Join(separator, " | ") + " |")
 
@@ -184,12 +224,10 @@ func emitX509Markdown(thisX509CertsWithRevocations []tX509CertificateProvisioner
 		// Building slice of columns within a single row.
 		var row []string
 		for _, column := range columns {
-			if column.isShown(config) {
-				if column.contentEscapeMD {
-					row = append(row, escapeMarkdown(column.contentSource(x509CertWithRevocation, config)))
-				} else {
-					row = append(row, column.contentSource(x509CertWithRevocation, config))
-				}
+			if column.contentEscapeMD {
+				row = append(row, escapeMarkdown(column.contentSource(x509CertWithRevocation, config)))
+			} else {
+				row = append(row, column.contentSource(x509CertWithRevocation, config))
 			}
 		}
 
@@ -3,8 +3,9 @@ module github.com/lukasz-lobocki/step-badger
 go 1.22.5
 
 require (
-	github.com/pkg/errors v0.9.1
+	github.com/dgraph-io/badger v1.6.2
 	github.com/spf13/cobra v1.8.1
+	go.etcd.io/bbolt v1.3.10
 )
 
 require (
@@ -13,7 +14,6 @@ require (
 	github.com/cespare/xxhash v1.1.0 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.4 // indirect
-	github.com/dgraph-io/badger v1.6.2 // indirect
 	github.com/dgraph-io/ristretto v0.1.1 // indirect
 	github.com/dgryski/go-farm v0.0.0-20200201041132-a6ae2369ad13 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
@@ -28,9 +28,9 @@ require (
 	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rogpeppe/go-internal v1.12.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	go.etcd.io/bbolt v1.3.10 // indirect
 	golang.org/x/net v0.27.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.22.0 // indirect
@@ -40,7 +40,7 @@ require (
 )
 
 require (
-	github.com/dgraph-io/badger/v2 v2.2007.4 // indirect
+	github.com/dgraph-io/badger/v2 v2.2007.4
 	github.com/fatih/color v1.17.0
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/lukasz-lobocki/tabby v1.0.6
@@ -0,0 +1,95 @@
+package stepdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	BADGER_MANIFEST   string = "MANIFEST" // Name of the badger manifest file.
+	BADGER_V1_VERSION uint32 = 4          // Manifest version written by badger v1.
+	BADGER_V2_VERSION uint32 = 7          // Manifest version written by badger v2.
+	BBOLT_MAGIC       uint32 = 0xED0CDAED // Magic number of the bbolt meta page.
+)
+
+/*
+DetectType inspects given location and returns the matching database type.
+
+A directory holding a badger MANIFEST is recognised as badger, with its major version
+taken from the manifest header. A file starting with a bbolt meta page is recognised as bbolt.
+
+	'thisPath' Location of the database.
+*/
+func DetectType(thisPath string) (string, error) {
+
+	info, err := os.Stat(thisPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		if hasBboltHeader(thisPath) {
+			return TYPE_BBOLT, nil
+		}
+		return "", fmt.Errorf("%q is neither a badger directory nor a bbolt file", thisPath)
+	}
+
+	header, err := readHeader(filepath.Join(thisPath, BADGER_MANIFEST), 8)
+	if err != nil {
+		return "", fmt.Errorf("no badger %s found in %q: %w", BADGER_MANIFEST, thisPath, err)
+	}
+	if !bytes.Equal(header[0:4], []byte("Bdgr")) {
+		return "", fmt.Errorf("%q is not a badger manifest", filepath.Join(thisPath, BADGER_MANIFEST))
+	}
+
+	switch version := binary.BigEndian.Uint32(header[4:8]); version {
+	case BADGER_V1_VERSION:
+		return TYPE_BADGERV1, nil
+	case BADGER_V2_VERSION:
+		return TYPE_BADGERV2, nil
+	default:
+		return "", fmt.Errorf("unsupported badger manifest version %d", version)
+	}
+}
+
+/*
+hasBboltHeader reports whether given file starts with a bbolt meta page.
+
+	'thisPath' Location of the file.
+*/
+func hasBboltHeader(thisPath string) bool {
+
+	// Page header is 16 bytes long, meta page's magic number follows it.
+	header, err := readHeader(thisPath, 20)
+	if err != nil {
+		return false
+	}
+
+	return binary.LittleEndian.Uint32(header[16:20]) == BBOLT_MAGIC
+}
+
+/*
+readHeader returns first bytes of given file.
+
+	'thisPath' Location of the file.
+	'thisLength' Number of bytes to be read.
+*/
+func readHeader(thisPath string, thisLength int) ([]byte, error) {
+
+	file, err := os.Open(thisPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header := make([]byte, thisLength)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
@@ -0,0 +1,64 @@
+package stepdb
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectType(t *testing.T) {
+
+	manifest := func(thisMagic string, thisVersion uint32) []byte {
+		return binary.BigEndian.AppendUint32([]byte(thisMagic), thisVersion)
+	}
+	bboltFile := func(thisMagic uint32) []byte {
+		return binary.LittleEndian.AppendUint32(make([]byte, 16), thisMagic) // Page header, then magic.
+	}
+
+	tests := []struct {
+		name     string
+		manifest []byte // Written as MANIFEST of a directory, nil for none.
+		file     []byte // Written as a file, nil for a directory.
+		want     string
+		wantErr  bool
+	}{
+		{"badger v1", manifest("Bdgr", BADGER_V1_VERSION), nil, TYPE_BADGERV1, false},
+		{"badger v2", manifest("Bdgr", BADGER_V2_VERSION), nil, TYPE_BADGERV2, false},
+		{"badger unsupported version", manifest("Bdgr", 8), nil, "", true},
+		{"badger wrong magic", manifest("Nope", BADGER_V2_VERSION), nil, "", true},
+		{"badger short manifest", []byte("Bdgr"), nil, "", true},
+		{"directory without manifest", nil, nil, "", true},
+		{"bbolt", nil, bboltFile(BBOLT_MAGIC), TYPE_BBOLT, false},
+		{"bbolt wrong magic", nil, bboltFile(0xDEADBEEF), "", true},
+		{"short file", nil, []byte("bolt"), "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := t.TempDir()
+			if test.file != nil {
+				path = filepath.Join(path, "step.db")
+				if err := os.WriteFile(path, test.file, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			} else if test.manifest != nil {
+				if err := os.WriteFile(filepath.Join(path, BADGER_MANIFEST), test.manifest, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			dbType, err := DetectType(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("DetectType() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if dbType != test.want {
+				t.Errorf("DetectType() = %q, want %q", dbType, test.want)
+			}
+		})
+	}
+
+	if _, err := DetectType(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("DetectType() of missing location succeeded")
+	}
+}
@@ -0,0 +1,207 @@
+package stepdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/smallstep/nosql/database"
+)
+
+/*
+Types of provisioners, as numbered by linkedca and stored in the provisioners bucket.
+*/
+const (
+	PROVISIONER_NOOP   int = 0
+	PROVISIONER_JWK    int = 1
+	PROVISIONER_OIDC   int = 2
+	PROVISIONER_GCP    int = 3
+	PROVISIONER_AWS    int = 4
+	PROVISIONER_AZURE  int = 5
+	PROVISIONER_ACME   int = 6
+	PROVISIONER_X5C    int = 7
+	PROVISIONER_K8SSA  int = 8
+	PROVISIONER_SSHPOP int = 9
+	PROVISIONER_SCEP   int = 10
+	PROVISIONER_NEBULA int = 11
+)
+
+/*
+ProvisionerTypeStr maps type of provisioner to its name, as step-ca stores it in x509_certs_data and ca.json.
+*/
+func ProvisionerTypeStr() map[int]string {
+	return map[int]string{
+		PROVISIONER_NOOP:   "NOOP",
+		PROVISIONER_JWK:    "JWK",
+		PROVISIONER_OIDC:   "OIDC",
+		PROVISIONER_GCP:    "GCP",
+		PROVISIONER_AWS:    "AWS",
+		PROVISIONER_AZURE:  "Azure",
+		PROVISIONER_ACME:   "ACME",
+		PROVISIONER_X5C:    "X5C",
+		PROVISIONER_K8SSA:  "K8sSA",
+		PROVISIONER_SSHPOP: "SSHPOP",
+		PROVISIONER_SCEP:   "SCEP",
+		PROVISIONER_NEBULA: "Nebula",
+	}
+}
+
+/*
+AdminProvisioner describes provisioner managed by remote administration, stored in the provisioners bucket.
+*/
+type AdminProvisioner struct {
+	ID          string    `json:"id"`
+	AuthorityID string    `json:"authorityID"`
+	Type        int       `json:"type"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"createdAt"`
+	DeletedAt   time.Time `json:"deletedAt"`
+}
+
+/*
+IsDeleted reports whether the provisioner was deleted. Deleted provisioners are kept in the bucket.
+*/
+func (thisProvisioner AdminProvisioner) IsDeleted() bool {
+	return !thisProvisioner.DeletedAt.IsZero()
+}
+
+/*
+ProvisionerIterator walks through the provisioners bucket.
+
+	for iterator.Next() {
+		provisioner, err := iterator.Record()
+	}
+*/
+type ProvisionerIterator struct {
+	entries []*database.Entry
+	index   int
+	err     error
+}
+
+/*
+Provisioners returns iterator over provisioners of the provisioners bucket, deleted ones included.
+*/
+func (thisReader *Reader) Provisioners() *ProvisionerIterator {
+
+	entries, err := thisReader.List(BUCKET_PROVISIONERS)
+
+	return &ProvisionerIterator{entries: entries, index: -1, err: err}
+}
+
+/*
+Next advances to the next record, returns false when there are none left.
+*/
+func (thisIterator *ProvisionerIterator) Next() bool {
+
+	if thisIterator.err != nil || thisIterator.index+1 >= len(thisIterator.entries) {
+		return false
+	}
+	thisIterator.index++
+
+	return true
+}
+
+/*
+Record returns current provisioner. Failure is reported as *RecordError.
+*/
+func (thisIterator *ProvisionerIterator) Record() (AdminProvisioner, error) {
+
+	entry := thisIterator.entries[thisIterator.index]
+
+	provisioner, err := ParseAdminProvisioner(entry.Value)
+	if err != nil {
+		return provisioner, &RecordError{Bucket: BUCKET_PROVISIONERS, Key: string(entry.Key), Err: err}
+	}
+
+	return provisioner, nil
+}
+
+/*
+Len returns number of records in the bucket.
+*/
+func (thisIterator *ProvisionerIterator) Len() int {
+	return len(thisIterator.entries)
+}
+
+/*
+Err returns error that prevented reading the bucket. Missing bucket gives error wrapping ErrBucketNotFound.
+*/
+func (thisIterator *ProvisionerIterator) Err() error {
+	return thisIterator.err
+}
+
+/*
+ParseAdminProvisioner decodes value of the provisioners bucket.
+
+	'thisValue' Raw value, json.
+*/
+func ParseAdminProvisioner(thisValue []byte) (AdminProvisioner, error) {
+
+	var provisioner AdminProvisioner
+
+	if err := json.Unmarshal(thisValue, &provisioner); err != nil {
+		return AdminProvisioner{}, fmt.Errorf("parsing provisioner: %w", err)
+	}
+
+	return provisioner, nil
+}
+
+/*
+LoadCAProvisioners returns provisioners of authority.provisioners section of given step-ca's ca.json.
+Their ID is derived the way step-ca does it, so that it matches ID stored in x509_certs_data.
+
+	'thisPath' Location of ca.json.
+*/
+func LoadCAProvisioners(thisPath string) ([]Provisioner, error) {
+
+	caConfigValue, err := os.ReadFile(thisPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var caConfig struct {
+		Authority struct {
+			Provisioners []struct {
+				Type     string `json:"type"`
+				Name     string `json:"name"`
+				ClientID string `json:"clientID"`
+				TenantID string `json:"tenantID"`
+				Key      struct {
+					KeyID string `json:"kid"`
+				} `json:"key"`
+			} `json:"provisioners"`
+		} `json:"authority"`
+	}
+	if err := json.Unmarshal(caConfigValue, &caConfig); err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", thisPath, err)
+	}
+
+	var provisioners []Provisioner
+	for _, caProvisioner := range caConfig.Authority.Provisioners {
+		provisioner := Provisioner{Name: caProvisioner.Name, Type: caProvisioner.Type}
+
+		// Canonical name of the type.
+		for _, typeStr := range ProvisionerTypeStr() {
+			if strings.EqualFold(typeStr, caProvisioner.Type) {
+				provisioner.Type = typeStr
+			}
+		}
+
+		switch provisioner.Type {
+		case "JWK":
+			provisioner.ID = caProvisioner.Name + ":" + caProvisioner.Key.KeyID
+		case "OIDC":
+			provisioner.ID = caProvisioner.ClientID
+		case "Azure":
+			provisioner.ID = caProvisioner.TenantID
+		default:
+			provisioner.ID = strings.ToLower(provisioner.Type) + "/" + caProvisioner.Name
+		}
+
+		provisioners = append(provisioners, provisioner)
+	}
+
+	return provisioners, nil
+}
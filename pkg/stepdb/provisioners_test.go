@@ -0,0 +1,72 @@
+package stepdb
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadCAProvisioners(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content string
+		want    []Provisioner
+		wantErr bool
+	}{
+		{"id derived by type", `{"authority": {"provisioners": [
+			{"type": "JWK", "name": "admin@example.com", "key": {"kid": "kid-1"}},
+			{"type": "OIDC", "name": "Google", "clientID": "client-1.apps.example.com"},
+			{"type": "Azure", "name": "Azure", "tenantID": "tenant-1"},
+			{"type": "ACME", "name": "acme"},
+			{"type": "X5C", "name": "x5c"},
+			{"type": "SSHPOP", "name": "sshpop"}
+		]}}`, []Provisioner{
+			{ID: "admin@example.com:kid-1", Name: "admin@example.com", Type: "JWK"},
+			{ID: "client-1.apps.example.com", Name: "Google", Type: "OIDC"},
+			{ID: "tenant-1", Name: "Azure", Type: "Azure"},
+			{ID: "acme/acme", Name: "acme", Type: "ACME"},
+			{ID: "x5c/x5c", Name: "x5c", Type: "X5C"},
+			{ID: "sshpop/sshpop", Name: "sshpop", Type: "SSHPOP"},
+		}, false},
+		{"type canonicalised", `{"authority": {"provisioners": [
+			{"type": "jwk", "name": "ops", "key": {"kid": "kid-2"}},
+			{"type": "acme", "name": "acme"},
+			{"type": "k8ssa", "name": "cluster"},
+			{"type": "azure", "name": "azure", "tenantID": "tenant-2"}
+		]}}`, []Provisioner{
+			{ID: "ops:kid-2", Name: "ops", Type: "JWK"},
+			{ID: "acme/acme", Name: "acme", Type: "ACME"},
+			{ID: "k8ssa/cluster", Name: "cluster", Type: "K8sSA"},
+			{ID: "tenant-2", Name: "azure", Type: "Azure"},
+		}, false},
+		{"explicit id ignored", `{"authority": {"provisioners": [{"type": "ACME", "name": "acme", "id": "custom"}]}}`,
+			[]Provisioner{{ID: "acme/acme", Name: "acme", Type: "ACME"}}, false},
+		{"unknown type kept", `{"authority": {"provisioners": [{"type": "Custom", "name": "mine"}]}}`,
+			[]Provisioner{{ID: "custom/mine", Name: "mine", Type: "Custom"}}, false},
+		{"no provisioners", `{"authority": {}}`, nil, false},
+		{"malformed", `{"authority": `, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ca.json")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			provisioners, err := LoadCAProvisioners(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("LoadCAProvisioners() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !slices.Equal(provisioners, test.want) {
+				t.Errorf("LoadCAProvisioners() = %+v, want %+v", provisioners, test.want)
+			}
+		})
+	}
+
+	if _, err := LoadCAProvisioners(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadCAProvisioners() of missing file succeeded")
+	}
+}
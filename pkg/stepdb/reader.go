@@ -0,0 +1,245 @@
+/*
+Package stepdb reads certificates, revocations and provisioners out of the database of step-ca.
+
+	reader, err := stepdb.Open(stepdb.Config{Type: stepdb.TYPE_AUTO, DataSource: "./db"}, stepdb.Options{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	certificates := reader.X509Certificates()
+	for certificates.Next() {
+		record, err := certificates.Record()
+		...
+	}
+	if err := certificates.Err(); err != nil {
+		return err
+	}
+
+Functions of this package never panic on malformed data, errors are returned instead.
+*/
+package stepdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/smallstep/nosql"
+	"github.com/smallstep/nosql/database"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	TYPE_AUTO     string = "auto"     // Type detected by inspecting the location.
+	TYPE_BADGER   string = "badger"   // Badger v1, as named by step-ca.
+	TYPE_BADGERV1 string = "badgerv1" // Badger v1.
+	TYPE_BADGERV2 string = "badgerv2" // Badger v2.
+	TYPE_BBOLT    string = "bbolt"    // BBolt.
+)
+
+/*
+Names of step-ca buckets.
+*/
+const (
+	BUCKET_X509_CERTS         string = "x509_certs"
+	BUCKET_X509_CERTS_DATA    string = "x509_certs_data"
+	BUCKET_REVOKED_X509_CERTS string = "revoked_x509_certs"
+	BUCKET_SSH_CERTS          string = "ssh_certs"
+	BUCKET_REVOKED_SSH_CERTS  string = "revoked_ssh_certs"
+	BUCKET_PROVISIONERS       string = "provisioners"
+)
+
+var (
+	ErrLocked         = errors.New("database is locked by another process") // Lock held by another process, most likely a running step-ca.
+	ErrBucketNotFound = errors.New("bucket not found")                      // Bucket does not exist in the database.
+)
+
+/*
+Config describes location of the database. It mirrors the db section of step-ca's ca.json.
+*/
+type Config struct {
+	Type                  string `json:"type"`
+	DataSource            string `json:"dataSource"`
+	ValueDir              string `json:"valueDir,omitempty"`
+	Database              string `json:"database,omitempty"`
+	BadgerFileLoadingMode string `json:"badgerFileLoadingMode,omitempty"`
+}
+
+/*
+Options tune the way the database is opened.
+*/
+type Options struct {
+	ReadOnly bool // Driver's read-only option is used, nothing is replayed or written.
+	Snapshot bool // Database files are copied into a temporary directory, the copy is opened.
+}
+
+/*
+Reader gives read access to the database of step-ca.
+*/
+type Reader struct {
+	db          database.DB
+	dbType      string
+	snapshotDir string
+}
+
+/*
+LoadCAConfig returns the db section of given step-ca's ca.json.
+
+	'thisPath' Location of ca.json.
+*/
+func LoadCAConfig(thisPath string) (Config, error) {
+
+	caConfigValue, err := os.ReadFile(thisPath)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var caConfig struct {
+		DB *Config `json:"db"`
+	}
+	if err := json.Unmarshal(caConfigValue, &caConfig); err != nil {
+		return Config{}, fmt.Errorf("parsing %q: %w", thisPath, err)
+	}
+	if caConfig.DB == nil || len(caConfig.DB.DataSource) == 0 {
+		return Config{}, fmt.Errorf("no db.dataSource found in %q", thisPath)
+	}
+
+	return *caConfig.DB, nil
+}
+
+/*
+Open opens the database the way step-ca does. Type "auto" is resolved by inspecting the location.
+Errors caused by the lock held by another process wrap ErrLocked.
+
+	'thisConfig' Location of the database.
+	'thisOptions' Way the database is opened.
+*/
+func Open(thisConfig Config, thisOptions Options) (*Reader, error) {
+
+	if thisOptions.ReadOnly && thisOptions.Snapshot {
+		return nil, errors.New("read-only and snapshot options are mutually exclusive")
+	}
+
+	dbType := strings.ToLower(thisConfig.Type)
+	if len(dbType) == 0 || dbType == TYPE_AUTO {
+		detectedType, err := DetectType(thisConfig.DataSource)
+		if err != nil {
+			return nil, err
+		}
+		dbType = detectedType
+	}
+
+	reader := &Reader{dbType: dbType}
+
+	var err error
+	switch {
+	case thisOptions.Snapshot:
+		var snapshotConfig Config
+		snapshotConfig, reader.snapshotDir, err = takeSnapshot(dbType, thisConfig)
+		if err != nil {
+			return nil, err
+		}
+		if reader.db, err = openNosql(dbType, snapshotConfig); err != nil {
+			os.RemoveAll(reader.snapshotDir)
+		}
+	case thisOptions.ReadOnly:
+		reader.db, err = openReadOnly(dbType, thisConfig)
+	default:
+		reader.db, err = openNosql(dbType, thisConfig)
+	}
+
+	if err != nil {
+		if isLockError(err) {
+			return nil, fmt.Errorf("%w: %q: %v", ErrLocked, thisConfig.DataSource, err)
+		}
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+/*
+Close closes the database, removing its temporary copy if one was taken.
+*/
+func (thisReader *Reader) Close() error {
+
+	err := thisReader.db.Close()
+
+	if len(thisReader.snapshotDir) > 0 {
+		if removeErr := os.RemoveAll(thisReader.snapshotDir); err == nil {
+			err = removeErr
+		}
+	}
+
+	return err
+}
+
+/*
+Type returns resolved type of the opened database.
+*/
+func (thisReader *Reader) Type() string {
+	return thisReader.dbType
+}
+
+/*
+SnapshotDir returns location of the temporary copy, empty if none was taken.
+*/
+func (thisReader *Reader) SnapshotDir() string {
+	return thisReader.snapshotDir
+}
+
+/*
+List returns all raw entries of given bucket. Missing bucket gives error wrapping ErrBucketNotFound.
+
+	'thisBucket' Name of the bucket.
+*/
+func (thisReader *Reader) List(thisBucket string) ([]*database.Entry, error) {
+
+	entries, err := thisReader.db.List([]byte(thisBucket))
+	if database.IsErrNotFound(err) {
+		return nil, fmt.Errorf("%w: %s", ErrBucketNotFound, thisBucket)
+	}
+
+	return entries, err
+}
+
+/*
+get returns raw value stored under given key, nil if there is none.
+
+	'thisBucket' Name of the bucket.
+	'thisKey' Key within the bucket.
+*/
+func (thisReader *Reader) get(thisBucket string, thisKey string) ([]byte, error) {
+
+	value, err := thisReader.db.Get([]byte(thisBucket), []byte(thisKey))
+	if database.IsErrNotFound(err) {
+		return nil, nil
+	}
+
+	return value, err
+}
+
+/*
+openNosql opens given database with the nosql driver, exactly as step-ca does.
+
+	'thisDbType' Resolved database type.
+	'thisConfig' Location of the database.
+*/
+func openNosql(thisDbType string, thisConfig Config) (database.DB, error) {
+	return nosql.New(thisDbType, thisConfig.DataSource,
+		database.WithValueDir(thisConfig.ValueDir),
+		database.WithDatabase(thisConfig.Database),
+		database.WithBadgerFileLoadingMode(thisConfig.BadgerFileLoadingMode))
+}
+
+/*
+isLockError reports whether given error comes from the database being locked by another process.
+
+	'thisErr' Error returned while opening the database.
+*/
+func isLockError(thisErr error) bool {
+	return errors.Is(thisErr, bolt.ErrTimeout) || strings.Contains(thisErr.Error(), "Cannot acquire directory lock")
+}
@@ -0,0 +1,210 @@
+package stepdb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/smallstep/nosql"
+	"github.com/smallstep/nosql/database"
+	bolt "go.etcd.io/bbolt"
+)
+
+/*
+newTestDatabase creates database of given type, the way step-ca does, holding given records of x509_certs.
+Returns its location.
+
+	'thisDbType' Database type.
+	'thisRecords' Values by key.
+*/
+func newTestDatabase(t *testing.T, thisDbType string, thisRecords map[string]string) string {
+	t.Helper()
+
+	path := t.TempDir()
+	options := []database.Option{database.WithValueDir(path)}
+	if thisDbType == TYPE_BBOLT {
+		path = filepath.Join(path, "step.db")
+		options = nil
+	}
+
+	db, err := nosql.New(thisDbType, path, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateTable([]byte(BUCKET_X509_CERTS)); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range thisRecords {
+		if err := db.Set([]byte(BUCKET_X509_CERTS), []byte(key), []byte(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestOpen(t *testing.T) {
+
+	records := map[string]string{"1": "first", "22": "second", "333": "third"}
+
+	for _, dbType := range []string{TYPE_BADGERV1, TYPE_BADGERV2, TYPE_BBOLT} {
+		path := newTestDatabase(t, dbType, records)
+
+		for _, test := range []struct {
+			name       string
+			configType string
+			options    Options
+		}{
+			{"auto", TYPE_AUTO, Options{}},
+			{"empty type", "", Options{}},
+			{"explicit type", dbType, Options{}},
+			{"read-only", TYPE_AUTO, Options{ReadOnly: true}},
+			{"snapshot", TYPE_AUTO, Options{Snapshot: true}},
+		} {
+			t.Run(dbType+" "+test.name, func(t *testing.T) {
+				reader, err := Open(Config{Type: test.configType, DataSource: path}, test.options)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if reader.Type() != dbType {
+					t.Errorf("Type() = %q, want %q", reader.Type(), dbType)
+				}
+
+				entries, err := reader.List(BUCKET_X509_CERTS)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := map[string]string{}
+				for _, entry := range entries {
+					if string(entry.Bucket) != BUCKET_X509_CERTS {
+						t.Errorf("entry of bucket %q", entry.Bucket)
+					}
+					got[string(entry.Key)] = string(entry.Value)
+				}
+				if fmt.Sprint(got) != fmt.Sprint(records) {
+					t.Errorf("List() = %v, want %v", got, records)
+				}
+
+				value, err := reader.get(BUCKET_X509_CERTS, "22")
+				if err != nil || string(value) != "second" {
+					t.Errorf("get() = %q, %v, want %q", value, err, "second")
+				}
+				if value, err := reader.get(BUCKET_X509_CERTS, "4"); value != nil || err != nil {
+					t.Errorf("get() of missing key = %q, %v, want nil", value, err)
+				}
+				if _, err := reader.List(BUCKET_SSH_CERTS); !errors.Is(err, ErrBucketNotFound) {
+					t.Errorf("List() of missing bucket error = %v, want %v", err, ErrBucketNotFound)
+				}
+
+				snapshotDir := reader.SnapshotDir()
+				if test.options.Snapshot == (len(snapshotDir) == 0) {
+					t.Errorf("SnapshotDir() = %q with snapshot %v", snapshotDir, test.options.Snapshot)
+				}
+				if err := reader.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if len(snapshotDir) > 0 {
+					if _, err := os.Stat(snapshotDir); !os.IsNotExist(err) {
+						t.Errorf("snapshot %q left after Close(), %v", snapshotDir, err)
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("read-only and snapshot", func(t *testing.T) {
+		if _, err := Open(Config{DataSource: t.TempDir()}, Options{ReadOnly: true, Snapshot: true}); err == nil {
+			t.Error("Open() succeeded")
+		}
+	})
+
+	t.Run("undetected", func(t *testing.T) {
+		if _, err := Open(Config{DataSource: t.TempDir()}, Options{}); err == nil {
+			t.Error("Open() succeeded")
+		}
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		path := newTestDatabase(t, TYPE_BADGERV2, records)
+		db, err := nosql.New(TYPE_BADGERV2, path, database.WithValueDir(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		if _, err := Open(Config{DataSource: path}, Options{}); !errors.Is(err, ErrLocked) {
+			t.Errorf("Open() error = %v, want %v", err, ErrLocked)
+		}
+	})
+}
+
+func TestIsLockError(t *testing.T) {
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"bbolt timeout", bolt.ErrTimeout, true},
+		{"bbolt timeout wrapped", fmt.Errorf("opening: %w", bolt.ErrTimeout), true},
+		{"badger directory lock", errors.New("Cannot acquire directory lock on \"/var/db\". Another process is using this Badger database.: resource temporarily unavailable"), true},
+		{"badger directory lock wrapped", fmt.Errorf("error opening Badger database: %w",
+			errors.New("Cannot acquire directory lock on \"/var/db\"")), true},
+		{"not found", os.ErrNotExist, false},
+		{"other", errors.New("manifest has unsupported version"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if isLock := isLockError(test.err); isLock != test.want {
+				t.Errorf("isLockError(%v) = %v, want %v", test.err, isLock, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadCAConfig(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content string
+		want    Config
+		wantErr bool
+	}{
+		{"badger", `{"db": {"type": "badgerv2", "dataSource": "/db", "valueDir": "/vlog", "badgerFileLoadingMode": "FileIO"}}`,
+			Config{Type: "badgerv2", DataSource: "/db", ValueDir: "/vlog", BadgerFileLoadingMode: "FileIO"}, false},
+		{"bbolt", `{"db": {"type": "bbolt", "dataSource": "/db/step.db", "database": "step"}, "authority": {}}`,
+			Config{Type: "bbolt", DataSource: "/db/step.db", Database: "step"}, false},
+		{"no type", `{"db": {"dataSource": "/db"}}`, Config{DataSource: "/db"}, false},
+		{"no db", `{"authority": {}}`, Config{}, true},
+		{"no data source", `{"db": {"type": "badgerv2"}}`, Config{}, true},
+		{"malformed", `{"db": `, Config{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ca.json")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadCAConfig(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("LoadCAConfig() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if config != test.want {
+				t.Errorf("LoadCAConfig() = %+v, want %+v", config, test.want)
+			}
+		})
+	}
+
+	if _, err := LoadCAConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadCAConfig() of missing file succeeded")
+	}
+}
@@ -0,0 +1,255 @@
+package stepdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	badgerv1 "github.com/dgraph-io/badger"
+	badgerv1options "github.com/dgraph-io/badger/options"
+	badgerv2 "github.com/dgraph-io/badger/v2"
+	badgerv2options "github.com/dgraph-io/badger/v2/options"
+	"github.com/smallstep/nosql/database"
+	bolt "go.etcd.io/bbolt"
+)
+
+/*
+tReadOnly refuses every modifying operation of database.DB. Embedded by read-only drivers.
+*/
+type tReadOnly struct{}
+
+func (tReadOnly) Open(_ string, _ ...database.Option) error { return database.ErrOpNotSupported }
+func (tReadOnly) Set(_, _, _ []byte) error                  { return database.ErrOpNotSupported }
+func (tReadOnly) Del(_, _ []byte) error                     { return database.ErrOpNotSupported }
+func (tReadOnly) Update(_ *database.Tx) error               { return database.ErrOpNotSupported }
+func (tReadOnly) CreateTable(_ []byte) error                { return database.ErrOpNotSupported }
+func (tReadOnly) DeleteTable(_ []byte) error                { return database.ErrOpNotSupported }
+func (tReadOnly) CmpAndSwap(_, _, _, _ []byte) ([]byte, bool, error) {
+	return nil, false, database.ErrOpNotSupported
+}
+
+/*
+openReadOnly opens given database with the driver's read-only option, so that nothing is replayed or written.
+
+	'thisDbType' Resolved database type.
+	'thisConfig' Location of the database.
+*/
+func openReadOnly(thisDbType string, thisConfig Config) (database.DB, error) {
+
+	valueDir := thisConfig.ValueDir
+	if len(valueDir) == 0 {
+		valueDir = thisConfig.DataSource
+	}
+	isFileIO := strings.ToLower(thisConfig.BadgerFileLoadingMode) == database.BadgerFileIO
+
+	switch thisDbType {
+	case TYPE_BADGERV1, TYPE_BADGER:
+		options := badgerv1.DefaultOptions(thisConfig.DataSource).WithValueDir(valueDir).WithReadOnly(true)
+		if isFileIO {
+			options = options.WithTableLoadingMode(badgerv1options.FileIO).WithValueLogLoadingMode(badgerv1options.FileIO)
+		}
+		db, err := badgerv1.Open(options)
+		if err != nil {
+			return nil, err
+		}
+		return &tBadgerV1ReadOnly{db: db}, nil
+
+	case TYPE_BADGERV2:
+		options := badgerv2.DefaultOptions(thisConfig.DataSource).WithValueDir(valueDir).WithReadOnly(true)
+		if isFileIO {
+			options = options.WithValueLogLoadingMode(badgerv2options.FileIO)
+		}
+		db, err := badgerv2.Open(options)
+		if err != nil {
+			return nil, err
+		}
+		return &tBadgerV2ReadOnly{db: db}, nil
+
+	case TYPE_BBOLT:
+		db, err := bolt.Open(thisConfig.DataSource, 0600, &bolt.Options{ReadOnly: true, Timeout: 5 * time.Second})
+		if err != nil {
+			return nil, err
+		}
+		return &tBboltReadOnly{db: db}, nil
+	}
+
+	return nil, fmt.Errorf("read-only mode is not supported for %s database", thisDbType)
+}
+
+/*
+Read-only badger v1 database.
+*/
+type tBadgerV1ReadOnly struct {
+	tReadOnly
+	db *badgerv1.DB
+}
+
+func (thisDB *tBadgerV1ReadOnly) Close() error { return thisDB.db.Close() }
+
+func (thisDB *tBadgerV1ReadOnly) Get(bucket, key []byte) (value []byte, err error) {
+	err = thisDB.db.View(func(txn *badgerv1.Txn) error {
+		item, err := txn.Get(toBadgerKey(bucket, key))
+		if err == badgerv1.ErrKeyNotFound {
+			return fmt.Errorf("key %s not found: %w", key, database.ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	return value, err
+}
+
+func (thisDB *tBadgerV1ReadOnly) List(bucket []byte) (entries []*database.Entry, err error) {
+	var tableExists bool
+	err = thisDB.db.View(func(txn *badgerv1.Txn) error {
+		iterator := txn.NewIterator(badgerv1.DefaultIteratorOptions)
+		defer iterator.Close()
+
+		prefix := badgerEncode(bucket)
+		for iterator.Seek(prefix); iterator.ValidForPrefix(prefix); iterator.Next() {
+			tableExists = true
+			value, err := iterator.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			entries = appendBadgerEntry(entries, bucket, iterator.Item().KeyCopy(nil), value)
+		}
+		return nil
+	})
+	if err == nil && !tableExists {
+		err = fmt.Errorf("bucket %s not found: %w", bucket, database.ErrNotFound)
+	}
+	return entries, err
+}
+
+/*
+Read-only badger v2 database.
+*/
+type tBadgerV2ReadOnly struct {
+	tReadOnly
+	db *badgerv2.DB
+}
+
+func (thisDB *tBadgerV2ReadOnly) Close() error { return thisDB.db.Close() }
+
+func (thisDB *tBadgerV2ReadOnly) Get(bucket, key []byte) (value []byte, err error) {
+	err = thisDB.db.View(func(txn *badgerv2.Txn) error {
+		item, err := txn.Get(toBadgerKey(bucket, key))
+		if err == badgerv2.ErrKeyNotFound {
+			return fmt.Errorf("key %s not found: %w", key, database.ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	return value, err
+}
+
+func (thisDB *tBadgerV2ReadOnly) List(bucket []byte) (entries []*database.Entry, err error) {
+	var tableExists bool
+	err = thisDB.db.View(func(txn *badgerv2.Txn) error {
+		iterator := txn.NewIterator(badgerv2.DefaultIteratorOptions)
+		defer iterator.Close()
+
+		prefix := badgerEncode(bucket)
+		for iterator.Seek(prefix); iterator.ValidForPrefix(prefix); iterator.Next() {
+			tableExists = true
+			value, err := iterator.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			entries = appendBadgerEntry(entries, bucket, iterator.Item().KeyCopy(nil), value)
+		}
+		return nil
+	})
+	if err == nil && !tableExists {
+		err = fmt.Errorf("bucket %s not found: %w", bucket, database.ErrNotFound)
+	}
+	return entries, err
+}
+
+/*
+Read-only bbolt database.
+*/
+type tBboltReadOnly struct {
+	tReadOnly
+	db *bolt.DB
+}
+
+func (thisDB *tBboltReadOnly) Close() error { return thisDB.db.Close() }
+
+func (thisDB *tBboltReadOnly) Get(bucket, key []byte) (value []byte, err error) {
+	err = thisDB.db.View(func(tx *bolt.Tx) error {
+		boltBucket := tx.Bucket(bucket)
+		if boltBucket == nil {
+			return fmt.Errorf("bucket %s not found: %w", bucket, database.ErrNotFound)
+		}
+		if value = boltBucket.Get(key); value == nil {
+			return fmt.Errorf("key %s not found: %w", key, database.ErrNotFound)
+		}
+		value = bytes.Clone(value)
+		return nil
+	})
+	return value, err
+}
+
+func (thisDB *tBboltReadOnly) List(bucket []byte) (entries []*database.Entry, err error) {
+	err = thisDB.db.View(func(tx *bolt.Tx) error {
+		boltBucket := tx.Bucket(bucket)
+		if boltBucket == nil {
+			return fmt.Errorf("bucket %s not found: %w", bucket, database.ErrNotFound)
+		}
+		return boltBucket.ForEach(func(key, value []byte) error {
+			entries = append(entries, &database.Entry{Bucket: bucket, Key: bytes.Clone(key), Value: bytes.Clone(value)})
+			return nil
+		})
+	})
+	return entries, err
+}
+
+/*
+toBadgerKey builds the key nosql stores in badger: length-prefixed bucket followed by length-prefixed key.
+
+	'thisBucket' Name of the bucket.
+	'thisKey' Key within the bucket.
+*/
+func toBadgerKey(thisBucket []byte, thisKey []byte) []byte {
+	return append(badgerEncode(thisBucket), badgerEncode(thisKey)...)
+}
+
+/*
+badgerEncode prefixes given value with its length, as two little endian bytes.
+
+	'thisValue' Value to be encoded.
+*/
+func badgerEncode(thisValue []byte) []byte {
+	return append(binary.LittleEndian.AppendUint16(nil, uint16(len(thisValue))), thisValue...)
+}
+
+/*
+appendBadgerEntry appends entry, stored under given badger key, to the slice. Bucket marker keys are skipped.
+
+	'thisEntries' Slice of entries.
+	'thisBucket' Name of the bucket.
+	'thisBadgerKey' Full badger key of the entry.
+	'thisValue' Value of the entry.
+*/
+func appendBadgerEntry(thisEntries []*database.Entry, thisBucket []byte, thisBadgerKey []byte, thisValue []byte) []*database.Entry {
+
+	rest := thisBadgerKey[2+len(thisBucket):]
+	if len(rest) < 2 {
+		return thisEntries // Bucket marker, holds no record.
+	}
+
+	return append(thisEntries, &database.Entry{
+		Bucket: thisBucket,
+		Key:    rest[2:],
+		Value:  thisValue,
+	})
+}
@@ -0,0 +1,75 @@
+package stepdb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/smallstep/nosql/database"
+)
+
+func TestToBadgerKey(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		bucket string
+		key    string
+		want   string // Hex.
+	}{
+		{"bucket and key", "x509_certs", "123", "0a00" + hex.EncodeToString([]byte("x509_certs")) + "0300313233"},
+		{"empty key", "ssh_certs", "", "0900" + hex.EncodeToString([]byte("ssh_certs")) + "0000"},
+		{"empty bucket", "", "k", "0000" + "01006b"},
+		{"key of 256 bytes", "b", strings.Repeat("k", 256), "010062" + "0001" + strings.Repeat("6b", 256)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := hex.DecodeString(test.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key := toBadgerKey([]byte(test.bucket), []byte(test.key)); !bytes.Equal(key, want) {
+				t.Errorf("toBadgerKey(%q, %q) = %x, want %x", test.bucket, test.key, key, want)
+			}
+		})
+	}
+}
+
+func TestAppendBadgerEntry(t *testing.T) {
+
+	bucket := []byte(BUCKET_X509_CERTS)
+
+	tests := []struct {
+		name      string
+		badgerKey []byte
+		want      *database.Entry // Nil if skipped.
+	}{
+		{"record", toBadgerKey(bucket, []byte("123")),
+			&database.Entry{Bucket: bucket, Key: []byte("123"), Value: []byte("value")}},
+		{"record of empty key", toBadgerKey(bucket, nil),
+			&database.Entry{Bucket: bucket, Key: []byte{}, Value: []byte("value")}},
+		{"bucket marker", badgerEncode(bucket), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries := appendBadgerEntry(nil, bucket, test.badgerKey, []byte("value"))
+			if test.want == nil {
+				if len(entries) != 0 {
+					t.Errorf("appendBadgerEntry() = %+v, want none", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("appendBadgerEntry() gave %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if !bytes.Equal(entry.Bucket, test.want.Bucket) || !bytes.Equal(entry.Key, test.want.Key) ||
+				!bytes.Equal(entry.Value, test.want.Value) {
+				t.Errorf("appendBadgerEntry() = %q/%q=%q, want %q/%q=%q", entry.Bucket, entry.Key, entry.Value,
+					test.want.Bucket, test.want.Key, test.want.Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package stepdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+Revocation describes revocation of a certificate. Both ssh & x509.
+*/
+type Revocation struct {
+	Serial        string    `json:"-"`
+	ProvisionerID string    `json:"ProvisionerID"`
+	ReasonCode    int       `json:"ReasonCode"`
+	Reason        string    `json:"Reason"`
+	RevokedAt     time.Time `json:"RevokedAt"`
+	ExpiresAt     time.Time `json:"ExpiresAt"`
+	TokenID       string    `json:"TokenID"`
+	MTLS          bool      `json:"MTLS"`
+	ACME          bool      `json:"ACME"`
+}
+
+/*
+IsRevoked reports whether the revocation record is present.
+*/
+func (thisRevocation Revocation) IsRevoked() bool {
+	return len(thisRevocation.ProvisionerID) > 0
+}
+
+/*
+Provisioner describes the provisioner that issued a certificate.
+*/
+type Provisioner struct {
+	ID   string `json:"ID"`
+	Name string `json:"Name"`
+	Type string `json:"Type"`
+}
+
+/*
+X509CertificateData holds additional data stored by step-ca along x509 certificate.
+*/
+type X509CertificateData struct {
+	Provisioner Provisioner `json:"Provisioner,omitempty"`
+	RaInfo      *string     `json:"-"`
+}
+
+/*
+RecordError describes a record that could not be read. The iteration may continue past it.
+*/
+type RecordError struct {
+	Bucket string
+	Key    string
+	Err    error
+}
+
+func (thisErr *RecordError) Error() string {
+	return fmt.Sprintf("%s/%s: %v", thisErr.Bucket, thisErr.Key, thisErr.Err)
+}
+
+func (thisErr *RecordError) Unwrap() error {
+	return thisErr.Err
+}
+
+/*
+ParseRevocation decodes revocation value. Empty value gives empty revocation.
+
+	'thisValue' Raw value of the revoked_x509_certs or revoked_ssh_certs bucket.
+*/
+func ParseRevocation(thisValue []byte) (Revocation, error) {
+
+	var revocation Revocation
+
+	if len(strings.TrimSpace(string(thisValue))) > 0 {
+		if err := json.Unmarshal(thisValue, &revocation); err != nil {
+			return Revocation{}, fmt.Errorf("parsing revocation: %w", err)
+		}
+	}
+
+	return revocation, nil
+}
+
+/*
+ParseX509CertificateData decodes certificate data value. Empty value gives empty data.
+
+	'thisValue' Raw value of the x509_certs_data bucket.
+*/
+func ParseX509CertificateData(thisValue []byte) (X509CertificateData, error) {
+
+	var certificateData X509CertificateData
+
+	if len(strings.TrimSpace(string(thisValue))) > 0 {
+		if err := json.Unmarshal(thisValue, &certificateData); err != nil {
+			return X509CertificateData{}, fmt.Errorf("parsing certificate data: %w", err)
+		}
+	}
+
+	return certificateData, nil
+}
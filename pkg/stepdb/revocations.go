@@ -0,0 +1,88 @@
+package stepdb
+
+import (
+	"github.com/smallstep/nosql/database"
+)
+
+/*
+RevocationIterator walks through the revoked_x509_certs or revoked_ssh_certs bucket.
+
+	for iterator.Next() {
+		revocation, err := iterator.Record()
+	}
+*/
+type RevocationIterator struct {
+	bucket  string
+	entries []*database.Entry
+	index   int
+	err     error
+}
+
+/*
+X509Revocations returns iterator over revocations of all x509 certificates, including those not found in x509_certs.
+*/
+func (thisReader *Reader) X509Revocations() *RevocationIterator {
+	return thisReader.revocations(BUCKET_REVOKED_X509_CERTS)
+}
+
+/*
+SSHRevocations returns iterator over revocations of all ssh certificates, including those not found in ssh_certs.
+*/
+func (thisReader *Reader) SSHRevocations() *RevocationIterator {
+	return thisReader.revocations(BUCKET_REVOKED_SSH_CERTS)
+}
+
+/*
+revocations returns iterator over given revocation bucket.
+
+	'thisBucket' Name of the bucket.
+*/
+func (thisReader *Reader) revocations(thisBucket string) *RevocationIterator {
+
+	entries, err := thisReader.List(thisBucket)
+
+	return &RevocationIterator{bucket: thisBucket, entries: entries, index: -1, err: err}
+}
+
+/*
+Next advances to the next record, returns false when there are none left.
+*/
+func (thisIterator *RevocationIterator) Next() bool {
+
+	if thisIterator.err != nil || thisIterator.index+1 >= len(thisIterator.entries) {
+		return false
+	}
+	thisIterator.index++
+
+	return true
+}
+
+/*
+Record returns current revocation, its Serial taken from the key. Failure is reported as *RecordError.
+*/
+func (thisIterator *RevocationIterator) Record() (Revocation, error) {
+
+	entry := thisIterator.entries[thisIterator.index]
+
+	revocation, err := ParseRevocation(entry.Value)
+	if err != nil {
+		return revocation, &RecordError{Bucket: thisIterator.bucket, Key: string(entry.Key), Err: err}
+	}
+	revocation.Serial = string(entry.Key)
+
+	return revocation, nil
+}
+
+/*
+Len returns number of records in the bucket.
+*/
+func (thisIterator *RevocationIterator) Len() int {
+	return len(thisIterator.entries)
+}
+
+/*
+Err returns error that prevented reading the bucket. Missing bucket gives error wrapping ErrBucketNotFound.
+*/
+func (thisIterator *RevocationIterator) Err() error {
+	return thisIterator.err
+}
@@ -0,0 +1,109 @@
+package stepdb
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+takeSnapshot copies the database files into a new temporary directory and returns description of the copy,
+along with the directory to be removed once done.
+
+Badger's SST, vlog, MANIFEST and KEYREGISTRY files are copied, the LOCK file is not.
+The bbolt file is copied as a whole.
+
+	'thisDbType' Resolved database type.
+	'thisConfig' Location of the database.
+*/
+func takeSnapshot(thisDbType string, thisConfig Config) (Config, string, error) {
+
+	snapshotDir, err := os.MkdirTemp("", "step-badger-")
+	if err != nil {
+		return Config{}, "", err
+	}
+
+	snapshotConfig := thisConfig
+	snapshotConfig.Type = thisDbType
+	snapshotConfig.ValueDir = ""
+
+	if thisDbType == TYPE_BBOLT {
+		snapshotConfig.DataSource = filepath.Join(snapshotDir, filepath.Base(thisConfig.DataSource))
+		err = copyFile(thisConfig.DataSource, snapshotConfig.DataSource)
+	} else {
+		snapshotConfig.DataSource = snapshotDir
+		err = copyBadgerFiles(thisConfig, snapshotDir)
+	}
+
+	if err != nil {
+		os.RemoveAll(snapshotDir)
+		return Config{}, "", fmt.Errorf("taking snapshot of %q: %w", thisConfig.DataSource, err)
+	}
+
+	return snapshotConfig, snapshotDir, nil
+}
+
+/*
+copyBadgerFiles copies badger's files from data and value directories into given directory.
+
+	'thisConfig' Location of the database.
+	'thisTargetDir' Directory to copy files into.
+*/
+func copyBadgerFiles(thisConfig Config, thisTargetDir string) error {
+
+	sourceDirs := []string{thisConfig.DataSource}
+	if len(thisConfig.ValueDir) > 0 && filepath.Clean(thisConfig.ValueDir) != filepath.Clean(thisConfig.DataSource) {
+		sourceDirs = append(sourceDirs, thisConfig.ValueDir)
+	}
+
+	for _, sourceDir := range sourceDirs {
+
+		dirEntries, err := os.ReadDir(sourceDir)
+		if err != nil {
+			return err
+		}
+
+		for _, dirEntry := range dirEntries {
+			name := dirEntry.Name()
+			if dirEntry.IsDir() || !(name == BADGER_MANIFEST || name == "KEYREGISTRY" ||
+				strings.HasSuffix(name, ".sst") || strings.HasSuffix(name, ".vlog")) {
+				continue
+			}
+
+			if err := copyFile(filepath.Join(sourceDir, name), filepath.Join(thisTargetDir, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+copyFile copies content of a single file.
+
+	'thisSource' Path of the file to be copied.
+	'thisTarget' Path of the copy.
+*/
+func copyFile(thisSource string, thisTarget string) error {
+
+	source, err := os.Open(thisSource)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(thisTarget, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(target, source); err != nil {
+		target.Close()
+		return err
+	}
+
+	return target.Close()
+}
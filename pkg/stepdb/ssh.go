@@ -0,0 +1,145 @@
+package stepdb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/smallstep/nosql/database"
+	"golang.org/x/crypto/ssh"
+)
+
+/*
+SSHCertificate combines ssh certificate with its revocation.
+*/
+type SSHCertificate struct {
+	Key         string
+	Value       []byte
+	Certificate *ssh.Certificate
+	Revocation  Revocation
+}
+
+/*
+SSHIterator walks through the ssh_certs bucket.
+
+	for iterator.Next() {
+		record, err := iterator.Record()
+	}
+*/
+type SSHIterator struct {
+	reader  *Reader
+	entries []*database.Entry
+	index   int
+	err     error
+}
+
+/*
+SSHCertificates returns iterator over all ssh certificates.
+*/
+func (thisReader *Reader) SSHCertificates() *SSHIterator {
+
+	entries, err := thisReader.List(BUCKET_SSH_CERTS)
+
+	return &SSHIterator{reader: thisReader, entries: entries, index: -1, err: err}
+}
+
+/*
+Next advances to the next record, returns false when there are none left.
+*/
+func (thisIterator *SSHIterator) Next() bool {
+
+	if thisIterator.err != nil || thisIterator.index+1 >= len(thisIterator.entries) {
+		return false
+	}
+	thisIterator.index++
+
+	return true
+}
+
+/*
+Record returns current record joined with its revocation. Failure is reported as *RecordError.
+*/
+func (thisIterator *SSHIterator) Record() (SSHCertificate, error) {
+
+	entry := thisIterator.entries[thisIterator.index]
+
+	record, err := thisIterator.reader.sshRecord(entry.Key, entry.Value)
+	if err != nil {
+		return record, &RecordError{Bucket: BUCKET_SSH_CERTS, Key: string(entry.Key), Err: err}
+	}
+
+	return record, nil
+}
+
+/*
+Len returns number of records in the bucket.
+*/
+func (thisIterator *SSHIterator) Len() int {
+	return len(thisIterator.entries)
+}
+
+/*
+Err returns error that prevented reading the bucket. Missing bucket gives error wrapping ErrBucketNotFound.
+*/
+func (thisIterator *SSHIterator) Err() error {
+	return thisIterator.err
+}
+
+/*
+SSHRevocation returns revocation of ssh certificate, empty if the certificate is not revoked.
+
+	'thisSerial' Decimal serial number.
+*/
+func (thisReader *Reader) SSHRevocation(thisSerial string) (Revocation, error) {
+
+	value, err := thisReader.get(BUCKET_REVOKED_SSH_CERTS, thisSerial)
+	if err != nil {
+		return Revocation{}, err
+	}
+
+	return ParseRevocation(value)
+}
+
+/*
+ParseSSHCertificate decodes value of the ssh_certs bucket.
+
+	'thisValue' Raw value, certificate in ssh wire format.
+*/
+func ParseSSHCertificate(thisValue []byte) (*ssh.Certificate, error) {
+
+	publicKey, err := ssh.ParsePublicKey(thisValue)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ssh certificate: %w", err)
+	}
+
+	sshCertificate, ok := publicKey.(*ssh.Certificate)
+	if !ok {
+		return nil, errors.New("key is not an ssh certificate")
+	}
+
+	return sshCertificate, nil
+}
+
+/*
+sshRecord parses certificate and joins it with its revocation.
+
+	'thisKey' Key of the ssh_certs bucket.
+	'thisValue' Value of the ssh_certs bucket.
+*/
+func (thisReader *Reader) sshRecord(thisKey []byte, thisValue []byte) (SSHCertificate, error) {
+
+	var (
+		record = SSHCertificate{Key: string(thisKey), Value: thisValue}
+		err    error
+	)
+
+	if record.Certificate, err = ParseSSHCertificate(thisValue); err != nil {
+		return record, err
+	}
+
+	if record.Revocation, err = thisReader.SSHRevocation(strconv.FormatUint(record.Certificate.Serial, 10)); err != nil {
+		return record, err
+	}
+
+	return record, nil
+}
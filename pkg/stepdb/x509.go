@@ -0,0 +1,176 @@
+package stepdb
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/smallstep/nosql/database"
+)
+
+/*
+X509Certificate combines x509 certificate with its revocation and data.
+*/
+type X509Certificate struct {
+	Key         string
+	Value       []byte
+	Certificate *x509.Certificate
+	Revocation  Revocation
+	Data        X509CertificateData
+}
+
+/*
+X509Iterator walks through the x509_certs bucket.
+
+	for iterator.Next() {
+		record, err := iterator.Record()
+	}
+*/
+type X509Iterator struct {
+	reader  *Reader
+	entries []*database.Entry
+	index   int
+	err     error
+}
+
+/*
+X509Certificates returns iterator over all x509 certificates.
+*/
+func (thisReader *Reader) X509Certificates() *X509Iterator {
+
+	entries, err := thisReader.List(BUCKET_X509_CERTS)
+
+	return &X509Iterator{reader: thisReader, entries: entries, index: -1, err: err}
+}
+
+/*
+Next advances to the next record, returns false when there are none left.
+*/
+func (thisIterator *X509Iterator) Next() bool {
+
+	if thisIterator.err != nil || thisIterator.index+1 >= len(thisIterator.entries) {
+		return false
+	}
+	thisIterator.index++
+
+	return true
+}
+
+/*
+Record returns current record joined with its revocation and data. Failure is reported as *RecordError.
+*/
+func (thisIterator *X509Iterator) Record() (X509Certificate, error) {
+
+	entry := thisIterator.entries[thisIterator.index]
+
+	record, err := thisIterator.reader.x509Record(entry.Key, entry.Value)
+	if err != nil {
+		return record, &RecordError{Bucket: BUCKET_X509_CERTS, Key: string(entry.Key), Err: err}
+	}
+
+	return record, nil
+}
+
+/*
+Len returns number of records in the bucket.
+*/
+func (thisIterator *X509Iterator) Len() int {
+	return len(thisIterator.entries)
+}
+
+/*
+Err returns error that prevented reading the bucket. Missing bucket gives error wrapping ErrBucketNotFound.
+*/
+func (thisIterator *X509Iterator) Err() error {
+	return thisIterator.err
+}
+
+/*
+X509Certificate returns x509 certificate of given serial number, joined with its revocation and data.
+Not found certificate gives error wrapping database.ErrNotFound.
+
+	'thisSerial' Decimal serial number.
+*/
+func (thisReader *Reader) X509Certificate(thisSerial string) (X509Certificate, error) {
+
+	value, err := thisReader.db.Get([]byte(BUCKET_X509_CERTS), []byte(thisSerial))
+	if err != nil {
+		return X509Certificate{}, err
+	}
+
+	return thisReader.x509Record([]byte(thisSerial), value)
+}
+
+/*
+X509Revocation returns revocation of x509 certificate, empty if the certificate is not revoked.
+
+	'thisSerial' Decimal serial number.
+*/
+func (thisReader *Reader) X509Revocation(thisSerial string) (Revocation, error) {
+
+	value, err := thisReader.get(BUCKET_REVOKED_X509_CERTS, thisSerial)
+	if err != nil {
+		return Revocation{}, err
+	}
+
+	return ParseRevocation(value)
+}
+
+/*
+X509CertificateData returns data stored along x509 certificate, empty if there is none.
+
+	'thisSerial' Decimal serial number.
+*/
+func (thisReader *Reader) X509CertificateData(thisSerial string) (X509CertificateData, error) {
+
+	value, err := thisReader.get(BUCKET_X509_CERTS_DATA, thisSerial)
+	if err != nil {
+		return X509CertificateData{}, err
+	}
+
+	return ParseX509CertificateData(value)
+}
+
+/*
+ParseX509Certificate decodes value of the x509_certs bucket.
+
+	'thisValue' Raw value, DER encoded certificate.
+*/
+func ParseX509Certificate(thisValue []byte) (*x509.Certificate, error) {
+
+	x509Certificate, err := x509.ParseCertificate(thisValue)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate: %w", err)
+	}
+
+	return x509Certificate, nil
+}
+
+/*
+x509Record parses certificate and joins it with its revocation and data.
+
+	'thisKey' Key of the x509_certs bucket.
+	'thisValue' Value of the x509_certs bucket.
+*/
+func (thisReader *Reader) x509Record(thisKey []byte, thisValue []byte) (X509Certificate, error) {
+
+	var (
+		record = X509Certificate{Key: string(thisKey), Value: thisValue}
+		err    error
+	)
+
+	if record.Certificate, err = ParseX509Certificate(thisValue); err != nil {
+		return record, err
+	}
+
+	serial := record.Certificate.SerialNumber.String()
+
+	if record.Revocation, err = thisReader.X509Revocation(serial); err != nil {
+		return record, err
+	}
+
+	if record.Data, err = thisReader.X509CertificateData(serial); err != nil {
+		return record, err
+	}
+
+	return record, nil
+}